@@ -0,0 +1,502 @@
+package folder
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/gofrs/uuid"
+)
+
+// Role is what a principal may do with folders, where
+// each role allows everything the roles before it do
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleReader
+	RoleWriter
+)
+
+// verb returns what the role allows, for error messages
+func (r Role) verb() string {
+	if r >= RoleWriter {
+		return "write"
+	}
+	return "read"
+}
+
+// Grant gives a principal a role on a folder and every
+// folder below it, following their parent links
+type Grant struct {
+	OrgID uuid.UUID
+	// Folder is the ID or exact path of the folder, which is
+	// looked up once, when the authorized driver is made
+	Folder string
+	Role   Role
+}
+
+// Principal is who the operations of an authorized
+// driver are made on behalf of
+type Principal struct {
+	Name string
+	// OrgRoles is the role the principal has on every
+	// folder of each organization
+	OrgRoles map[uuid.UUID]Role
+	// Grants add to the roles in OrgRoles, for part of
+	// an organization
+	Grants []Grant
+}
+
+// authorizedDriver makes the operations of a driver on behalf
+// of a principal, checking their roles first. Folders the
+// principal cannot read are left out of every result.
+type authorizedDriver struct {
+	inner     *driver
+	principal Principal
+	// granted is the FileNode of each of the Grants of
+	// the principal, in the same order
+	granted []*FileNode
+}
+
+// NewAuthorizedDriver returns an IDriver which makes the
+// operations of 'd', a driver returned by NewDriver, on
+// behalf of 'principal'. Moving a folder needs RoleWriter on
+// its parent, or its organization for a root folder, and on
+// the destination. Every other operation needs RoleReader on
+// the folders it is given. A NotFoundError is returned for a
+// grant whose folder does not exist.
+func NewAuthorizedDriver(d IDriver, principal Principal) (IDriver, error) {
+	inner, ok := d.(*driver)
+	if !ok {
+		return nil, errors.New("error: driver does not support access control")
+	}
+
+	granted := []*FileNode{}
+	for _, grant := range principal.Grants {
+		fileNode := inner.findGranted(grant)
+		if fileNode == nil {
+			return nil, &NotFoundError{Kind: KindGrantedFolder, OrgID: grant.OrgID, Ref: grant.Folder}
+		}
+		granted = append(granted, fileNode)
+	}
+
+	return &authorizedDriver{
+		inner:     inner,
+		principal: principal,
+		granted:   granted,
+	}, nil
+}
+
+// findGranted returns the FileNode whose ID or path is the
+// folder of 'grant', or nil. Names are not looked up, as a
+// grant must not change folders when another is named alike.
+func (f *driver) findGranted(grant Grant) *FileNode {
+	org, exists := f.orgs[grant.OrgID]
+	if !exists {
+		return nil
+	}
+	if id, err := uuid.FromString(grant.Folder); err == nil {
+		return lookupID(org, id)
+	}
+	if org.index == nil {
+		return nil
+	}
+
+	return org.index.Lookup(f.normalizeRef(grant.Folder))
+}
+
+// role returns the role the principal has on 'fileNode',
+// from its organization or a grant on it or above it
+func (a *authorizedDriver) role(fileNode *FileNode) Role {
+	orgID := fileNode.file.OrgId
+	role := a.principal.OrgRoles[orgID]
+	for i, grant := range a.principal.Grants {
+		if grant.OrgID != orgID || grant.Role <= role {
+			continue
+		}
+		if granted := a.granted[i]; granted == fileNode || IsDescendant(fileNode, granted) {
+			role = grant.Role
+		}
+	}
+
+	return role
+}
+
+// require returns a PermissionError if the principal does
+// not have the role 'need' on 'fileNode'
+func (a *authorizedDriver) require(fileNode *FileNode, need Role, kind string, ref string) error {
+	if a.role(fileNode) >= need {
+		return nil
+	}
+
+	return &PermissionError{
+		Kind:      kind,
+		OrgID:     fileNode.file.OrgId,
+		Principal: a.principal.Name,
+		Ref:       ref,
+		Need:      need,
+	}
+}
+
+// requireOrg returns a PermissionError if the principal does
+// not have the role 'need' on the whole organization
+func (a *authorizedDriver) requireOrg(orgID uuid.UUID, need Role) error {
+	if a.principal.OrgRoles[orgID] >= need {
+		return nil
+	}
+
+	return &PermissionError{
+		Kind:      KindOrganization,
+		OrgID:     orgID,
+		Principal: a.principal.Name,
+		Ref:       orgID.String(),
+		Need:      need,
+	}
+}
+
+// readNode returns the FileNode with 'ref' if the principal
+// can read it. Not found errors have no suggestions, as
+// they could name folders the principal cannot read.
+func (a *authorizedDriver) readNode(orgID uuid.UUID, ref string) (*FileNode, error) {
+	fileNode, err := a.inner.findNode(orgID, ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.require(fileNode, RoleReader, KindFolder, ref); err != nil {
+		return nil, err
+	}
+
+	return fileNode, nil
+}
+
+// canRead returns whether the principal can read 'folder',
+// as it is currently stored by the driver
+func (a *authorizedDriver) canRead(folder Folder) bool {
+	org := a.inner.orgs[folder.OrgId]
+	fileNode := lookupID(org, folder.ID)
+	if fileNode == nil && org.index != nil {
+		fileNode = org.index.Lookup(folder.Paths)
+	}
+
+	return fileNode != nil && a.role(fileNode) >= RoleReader
+}
+
+// readable returns the Folders in 'folders' which the
+// principal can read
+func (a *authorizedDriver) readable(folders []Folder) []Folder {
+	res := []Folder{}
+	for _, folder := range folders {
+		if a.canRead(folder) {
+			res = append(res, folder)
+		}
+	}
+
+	return res
+}
+
+func (a *authorizedDriver) GetFoldersByOrgID(orgID uuid.UUID) []Folder {
+	return a.readable(a.inner.GetFoldersByOrgID(orgID))
+}
+
+func (a *authorizedDriver) GetFoldersByOrgIDWithFilter(orgID uuid.UUID, filter FolderFilter) []Folder {
+	return a.readable(a.inner.GetFoldersByOrgIDWithFilter(orgID, filter))
+}
+
+func (a *authorizedDriver) GetAllChildFolders(orgID uuid.UUID, name string) []Folder {
+	return a.readable(a.inner.GetAllChildFolders(orgID, name))
+}
+
+// checkMove returns the error for moving 'srcFolder' to
+// 'dstFolder' on behalf of the principal, or nil if the
+// principal may make the move and it is allowed
+func (a *authorizedDriver) checkMove(srcFolder *FileNode, dstFolder *FileNode, src string, dst string, orgID uuid.UUID) error {
+	if srcFolder == nil {
+		return &NotFoundError{Kind: KindSourceFolder, OrgID: orgID, Ref: src}
+	}
+	if dstFolder == nil {
+		return &NotFoundError{Kind: KindDestinationFolder, OrgID: orgID, Ref: dst}
+	}
+
+	var err error
+	if srcFolder.parent != nil {
+		err = a.require(srcFolder.parent, RoleWriter, KindParent, src)
+	} else {
+		err = a.requireOrg(srcFolder.file.OrgId, RoleWriter)
+	}
+	if err != nil {
+		return err
+	}
+	if err := a.require(dstFolder, RoleWriter, KindDestinationFolder, dst); err != nil {
+		return err
+	}
+
+	return a.hideConflict(a.inner.checkMove(srcFolder, dstFolder, src, dst, orgID))
+}
+
+// hideConflict returns 'err' without the path of the
+// existing folder of a NameConflictError, if the principal
+// cannot read that folder
+func (a *authorizedDriver) hideConflict(err error) error {
+	conflict := &NameConflictError{}
+	if !errors.As(err, &conflict) {
+		return err
+	}
+
+	org := a.inner.orgs[conflict.OrgID]
+	if org.index != nil {
+		if existing := org.index.Lookup(conflict.Existing); existing != nil && a.role(existing) >= RoleReader {
+			return err
+		}
+	}
+
+	return &NameConflictError{OrgID: conflict.OrgID, Name: conflict.Name}
+}
+
+func (a *authorizedDriver) MoveFolder(name string, dst string) ([]Folder, error) {
+	srcFolder, dstFolder := a.inner.findMoveNodes(uuid.Nil, name, dst)
+	if err := a.checkMove(srcFolder, dstFolder, name, dst, uuid.Nil); err != nil {
+		return []Folder{}, err
+	}
+	a.inner.moveNode(srcFolder, dstFolder)
+
+	return a.readable(CreateFolderSlice(a.inner.orgs)), nil
+}
+
+// CreateFolder needs RoleWriter on the parent, or the
+// organization for a root folder
+func (a *authorizedDriver) CreateFolder(orgID uuid.UUID, name string, parent string) (Folder, error) {
+	if parent == "" {
+		if err := a.requireOrg(orgID, RoleWriter); err != nil {
+			return Folder{}, err
+		}
+		return a.inner.CreateFolder(orgID, name, parent)
+	}
+
+	org, err := a.inner.findOrg(orgID)
+	if err != nil {
+		return Folder{}, err
+	}
+	parentNode := lookupNode(org, a.inner.normalizeRef(parent))
+	if parentNode != nil {
+		if err := a.require(parentNode, RoleWriter, KindParent, parent); err != nil {
+			return Folder{}, err
+		}
+	}
+
+	folder, err := a.inner.CreateFolder(orgID, name, parent)
+	return folder, a.hideConflict(err)
+}
+
+func (a *authorizedDriver) DryRunMove(orgID uuid.UUID, src string, dst string) ([]MoveChange, error) {
+	if _, err := a.inner.findOrg(orgID); err != nil {
+		return []MoveChange{}, err
+	}
+
+	srcFolder, dstFolder := a.inner.findMoveNodes(orgID, src, dst)
+	if err := a.checkMove(srcFolder, dstFolder, src, dst, orgID); err != nil {
+		return []MoveChange{}, err
+	}
+
+	return PlanMove(srcFolder, dstFolder, a.inner.codec), nil
+}
+
+func (a *authorizedDriver) Stats(orgID uuid.UUID, path string) (FolderStats, error) {
+	if _, err := a.readNode(orgID, path); err != nil {
+		return FolderStats{}, err
+	}
+
+	return a.inner.Stats(orgID, path)
+}
+
+func (a *authorizedDriver) OrgStats(orgID uuid.UUID) (FolderStats, error) {
+	if _, err := a.inner.findOrg(orgID); err != nil {
+		return FolderStats{}, err
+	}
+	if err := a.requireOrg(orgID, RoleReader); err != nil {
+		return FolderStats{}, err
+	}
+
+	return a.inner.OrgStats(orgID)
+}
+
+func (a *authorizedDriver) DescendantCount(orgID uuid.UUID, path string) (int, error) {
+	if _, err := a.readNode(orgID, path); err != nil {
+		return 0, err
+	}
+
+	return a.inner.DescendantCount(orgID, path)
+}
+
+func (a *authorizedDriver) GetAncestors(orgID uuid.UUID, path string) ([]Folder, error) {
+	if _, err := a.readNode(orgID, path); err != nil {
+		return []Folder{}, err
+	}
+
+	ancestors, err := a.inner.GetAncestors(orgID, path)
+	return a.readable(ancestors), err
+}
+
+func (a *authorizedDriver) GetParent(orgID uuid.UUID, path string) (Folder, error) {
+	fileNode, err := a.readNode(orgID, path)
+	if err != nil {
+		return Folder{}, err
+	}
+	if fileNode.parent != nil {
+		if err := a.require(fileNode.parent, RoleReader, KindParent, path); err != nil {
+			return Folder{}, err
+		}
+	}
+
+	return a.inner.GetParent(orgID, path)
+}
+
+func (a *authorizedDriver) GetSiblings(orgID uuid.UUID, path string) ([]Folder, error) {
+	if _, err := a.readNode(orgID, path); err != nil {
+		return []Folder{}, err
+	}
+
+	siblings, err := a.inner.GetSiblings(orgID, path)
+	return a.readable(siblings), err
+}
+
+func (a *authorizedDriver) GetImmediateChildren(orgID uuid.UUID, path string) ([]Folder, error) {
+	if _, err := a.readNode(orgID, path); err != nil {
+		return []Folder{}, err
+	}
+
+	children, err := a.inner.GetImmediateChildren(orgID, path)
+	return a.readable(children), err
+}
+
+// GetAllChildFoldersWithOptions leaves folders the principal
+// cannot read out before pages are cut, so each page is full
+// and its cursor is the path of a folder the principal can read
+func (a *authorizedDriver) GetAllChildFoldersWithOptions(orgID uuid.UUID, name string, opts ChildListOptions) (ChildPage, error) {
+	fileNode, err := a.readNode(orgID, name)
+	if err != nil {
+		return ChildPage{Folders: []Folder{}}, err
+	}
+
+	return a.inner.childPage(fileNode, opts, func(childNode *FileNode) bool {
+		return a.role(childNode) >= RoleReader
+	})
+}
+
+func (a *authorizedDriver) ChildFolders(orgID uuid.UUID, name string, maxDepth int) (iter.Seq[Folder], error) {
+	if _, err := a.inner.findNode(orgID, name); err != nil {
+		return func(yield func(Folder) bool) {}, err
+	}
+
+	children, err := a.inner.ChildFolders(orgID, name, maxDepth)
+	return func(yield func(Folder) bool) {
+		for folder := range children {
+			if a.canRead(folder) && !yield(folder) {
+				return
+			}
+		}
+	}, err
+}
+
+func (a *authorizedDriver) Walk(orgID uuid.UUID, path string, order WalkOrder) (iter.Seq[Visit], error) {
+	if path != "" {
+		if _, err := a.inner.findNode(orgID, path); err != nil {
+			return WalkNodes(nil, order), err
+		}
+	}
+
+	visits, err := a.inner.Walk(orgID, path, order)
+	return func(yield func(Visit) bool) {
+		for visit := range visits {
+			if a.canRead(visit.Folder) && !yield(visit) {
+				return
+			}
+		}
+	}, err
+}
+
+func (a *authorizedDriver) LowestCommonAncestor(orgID uuid.UUID, first string, second string) (Folder, error) {
+	if err := a.readBoth(orgID, first, second); err != nil {
+		return Folder{}, err
+	}
+
+	ancestor, err := a.inner.LowestCommonAncestor(orgID, first, second)
+	if err == nil && !a.canRead(ancestor) {
+		return Folder{}, &PermissionError{
+			Kind:      KindCommonAncestor,
+			OrgID:     orgID,
+			Principal: a.principal.Name,
+			Ref:       first,
+			Need:      RoleReader,
+		}
+	}
+	return ancestor, err
+}
+
+func (a *authorizedDriver) Distance(orgID uuid.UUID, first string, second string) (int, error) {
+	if err := a.readBoth(orgID, first, second); err != nil {
+		return 0, err
+	}
+
+	return a.inner.Distance(orgID, first, second)
+}
+
+func (a *authorizedDriver) RelativePath(orgID uuid.UUID, first string, second string) (PathDiff, error) {
+	if err := a.readBoth(orgID, first, second); err != nil {
+		return PathDiff{}, err
+	}
+
+	return a.inner.RelativePath(orgID, first, second)
+}
+
+// readBoth returns an error unless the principal can
+// read both of the folders being related
+func (a *authorizedDriver) readBoth(orgID uuid.UUID, first string, second string) error {
+	if _, err := a.readNode(orgID, first); err != nil {
+		return err
+	}
+	_, err := a.readNode(orgID, second)
+	return err
+}
+
+// Search applies the limit in 'opts' after leaving out the
+// folders the principal cannot read
+func (a *authorizedDriver) Search(orgID uuid.UUID, query string, opts SearchOptions) ([]Folder, error) {
+	limit := opts.Limit
+	opts.Limit = 0
+	found, err := a.inner.Search(orgID, query, opts)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	found = a.readable(found)
+	if limit > 0 && len(found) > limit {
+		found = found[:limit]
+	}
+	return found, nil
+}
+
+// LoadReport leaves out the orphans the principal cannot
+// read, and the folders created for them it cannot read
+func (a *authorizedDriver) LoadReport() LoadReport {
+	report := a.inner.LoadReport()
+	orphans := []Orphan{}
+	for _, orphan := range report.Orphans {
+		if a.canRead(orphan.Folder) {
+			orphan.Created = a.readable(orphan.Created)
+			orphans = append(orphans, orphan)
+		}
+	}
+	report.Orphans = orphans
+
+	return report
+}
+
+// CheckInvariants needs RoleReader on the organization, as
+// violations can be of any folder, and returns none without it
+func (a *authorizedDriver) CheckInvariants(orgID uuid.UUID) []Violation {
+	if err := a.requireOrg(orgID, RoleReader); err != nil {
+		return []Violation{}
+	}
+
+	return a.inner.CheckInvariants(orgID)
+}
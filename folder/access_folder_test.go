@@ -0,0 +1,270 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// kim can only read the subtree starting at alpha.bravo
+var kim = folder.Principal{
+	Name: "kim",
+	Grants: []folder.Grant{
+		{OrgID: uuid.FromStringOrNil(folder.DefaultOrgID), Folder: "alpha.bravo", Role: folder.RoleReader},
+	},
+}
+
+// sam can read the whole organization, and write to the
+// subtrees starting at alpha.delta and golf
+var sam = folder.Principal{
+	Name: "sam",
+	OrgRoles: map[uuid.UUID]folder.Role{
+		uuid.FromStringOrNil(folder.DefaultOrgID): folder.RoleReader,
+	},
+	Grants: []folder.Grant{
+		{OrgID: uuid.FromStringOrNil(folder.DefaultOrgID), Folder: "alpha.delta", Role: folder.RoleWriter},
+		{OrgID: uuid.FromStringOrNil(folder.DefaultOrgID), Folder: "golf", Role: folder.RoleWriter},
+	},
+}
+
+func newAuthorizedDriver(t *testing.T, principal folder.Principal) folder.IDriver {
+	t.Helper()
+	f, err := folder.NewAuthorizedDriver(folder.NewDriver(append([]folder.Folder{}, exampleFolders...)), principal)
+	if err != nil {
+		t.Fatalf("NewAuthorizedDriver() = %v, want nil for error", err)
+	}
+
+	return f
+}
+
+func checkPermission(t *testing.T, method string, err error, want error) {
+	t.Helper()
+	if want == nil {
+		if err != nil {
+			t.Errorf("%s = %v, want nil for error", method, err)
+		}
+		return
+	}
+	if err == nil || err.Error() != want.Error() {
+		t.Errorf("%s = %v, want %v for error", method, err, want)
+	} else if !errors.Is(err, folder.ErrPermissionDenied) {
+		t.Errorf("%s = %v, want a PermissionError", method, err)
+	}
+}
+
+func Test_folder_AuthorizedDriver_Read(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := newAuthorizedDriver(t, kim)
+	bravo := exampleFolders[1:3]
+
+	if get := f.GetFoldersByOrgID(orgID); !reflect.DeepEqual(get, bravo) {
+		t.Errorf("GetFoldersByOrgID() = %v, want %v", get, bravo)
+	}
+	if get := f.GetAllChildFolders(orgID, "alpha"); !reflect.DeepEqual(get, bravo) {
+		t.Errorf("GetAllChildFolders() = %v, want %v", get, bravo)
+	}
+
+	parent, err := f.GetParent(orgID, "charlie")
+	checkPermission(t, "GetParent()", err, nil)
+	if !reflect.DeepEqual(parent, exampleFolders[1]) {
+		t.Errorf("GetParent() = %v, want %v", parent, exampleFolders[1])
+	}
+	_, err = f.GetParent(orgID, "bravo")
+	checkPermission(t, "GetParent()", err, errors.New("error: permission denied to read parent folder"))
+
+	ancestors, err := f.GetAncestors(orgID, "alpha.bravo.charlie")
+	checkPermission(t, "GetAncestors()", err, nil)
+	if !reflect.DeepEqual(ancestors, exampleFolders[1:2]) {
+		t.Errorf("GetAncestors() = %v, want %v", ancestors, exampleFolders[1:2])
+	}
+
+	_, err = f.Stats(orgID, "alpha.delta")
+	checkPermission(t, "Stats()", err, errors.New("error: permission denied to read folder"))
+	_, err = f.OrgStats(orgID)
+	checkPermission(t, "OrgStats()", err, errors.New("error: permission denied to read organization"))
+
+	_, err = f.Distance(orgID, "charlie", "echo")
+	checkPermission(t, "Distance()", err, errors.New("error: permission denied to read folder"))
+
+	visits, err := f.Walk(orgID, "", folder.PreOrder)
+	checkPermission(t, "Walk()", err, nil)
+	walked := []folder.Folder{}
+	for visit := range visits {
+		walked = append(walked, visit.Folder)
+	}
+	if !reflect.DeepEqual(walked, bravo) {
+		t.Errorf("Walk() = %v, want %v", walked, bravo)
+	}
+
+	found, err := f.Search(orgID, "", folder.SearchOptions{Mode: folder.SearchSubstring, Limit: 1})
+	checkPermission(t, "Search()", err, nil)
+	if !reflect.DeepEqual(found, exampleFolders[1:2]) {
+		t.Errorf("Search() = %v, want %v", found, exampleFolders[1:2])
+	}
+}
+
+func Test_folder_AuthorizedDriver_Move(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name string
+		src  string
+		dst  string
+		err  error
+	}{
+		{
+			name: "Write on source parent and destination",
+			src:  "echo",
+			dst:  "golf",
+		},
+		{
+			name: "No write on source parent",
+			src:  "bravo",
+			dst:  "delta",
+			err:  errors.New("error: permission denied to write parent folder"),
+		},
+		{
+			name: "No write on destination",
+			src:  "echo",
+			dst:  "bravo",
+			err:  errors.New("error: permission denied to write destination folder"),
+		},
+		{
+			name: "Root folder needs write on organization",
+			src:  "golf",
+			dst:  "delta",
+			err:  errors.New("error: permission denied to write organization"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAuthorizedDriver(t, sam)
+			orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+
+			_, err := f.DryRunMove(orgID, tt.src, tt.dst)
+			checkPermission(t, "DryRunMove()", err, tt.err)
+
+			get, err := f.MoveFolder(tt.src, tt.dst)
+			checkPermission(t, "MoveFolder()", err, tt.err)
+			if tt.err != nil {
+				return
+			}
+
+			// foxtrot is in an organization sam cannot read
+			for _, moved := range get {
+				if moved.Name == "foxtrot" {
+					t.Errorf("MoveFolder() returned %v, which sam cannot read", moved)
+				}
+			}
+			if _, err := f.GetParent(orgID, tt.src); err != nil {
+				t.Errorf("GetParent() = %v after moving, want nil for error", err)
+			}
+		})
+	}
+}
+
+func Test_folder_AuthorizedDriver_Create(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := newAuthorizedDriver(t, sam)
+
+	_, err := f.CreateFolder(orgID, "hotel", "golf")
+	checkPermission(t, "CreateFolder()", err, nil)
+	_, err = f.CreateFolder(orgID, "india", "alpha")
+	checkPermission(t, "CreateFolder()", err, errors.New("error: permission denied to write parent folder"))
+	_, err = f.CreateFolder(orgID, "india", "")
+	checkPermission(t, "CreateFolder()", err, errors.New("error: permission denied to write organization"))
+}
+
+func Test_folder_NewAuthorizedDriver(t *testing.T) {
+	t.Parallel()
+	f := newAuthorizedDriver(t, sam)
+
+	_, err := folder.NewAuthorizedDriver(f, kim)
+	if err == nil || err.Error() != "error: driver does not support access control" {
+		t.Errorf("NewAuthorizedDriver() = %v, want error for an authorized driver", err)
+	}
+}
+
+func Test_folder_NewAuthorizedDriver_Grants(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	bravoID := uuid.Must(uuid.NewV4())
+	folders := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{ID: bravoID, Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+	}
+	tests := [...]struct {
+		name  string
+		grant folder.Grant
+		want  error
+	}{
+		{
+			name:  "Grant by ID",
+			grant: folder.Grant{OrgID: orgID, Folder: bravoID.String(), Role: folder.RoleReader},
+			want:  nil,
+		},
+		{
+			name:  "Grant by path",
+			grant: folder.Grant{OrgID: orgID, Folder: "alpha.bravo", Role: folder.RoleReader},
+			want:  nil,
+		},
+		{
+			name:  "Grant by name",
+			grant: folder.Grant{OrgID: orgID, Folder: "bravo", Role: folder.RoleReader},
+			want:  errors.New("error: granted folder does not exist"),
+		},
+		{
+			name:  "Grant in another organization",
+			grant: folder.Grant{OrgID: uuid.Must(uuid.NewV4()), Folder: "alpha.bravo", Role: folder.RoleReader},
+			want:  errors.New("error: granted folder does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal := folder.Principal{Name: "lee", Grants: []folder.Grant{tt.grant}}
+			f, err := folder.NewAuthorizedDriver(folder.NewDriver(append([]folder.Folder{}, folders...)), principal)
+			checkNotFound(t, "NewAuthorizedDriver()", err, tt.want)
+			if err != nil {
+				return
+			}
+
+			want := folders[1:]
+			if get := f.GetFoldersByOrgID(orgID); !reflect.DeepEqual(get, want) {
+				t.Errorf("GetFoldersByOrgID() = %v, want %v", get, want)
+			}
+		})
+	}
+}
+
+func Test_folder_AuthorizedDriver_ChildPages(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+
+	f := newAuthorizedDriver(t, kim)
+	_, err := f.GetAllChildFoldersWithOptions(orgID, "alpha", folder.ChildListOptions{PageSize: 1})
+	checkPermission(t, "GetAllChildFoldersWithOptions()", err, errors.New("error: permission denied to read folder"))
+	_, err = f.GetImmediateChildren(orgID, "alpha")
+	checkPermission(t, "GetImmediateChildren()", err, errors.New("error: permission denied to read folder"))
+
+	f = newAuthorizedDriver(t, sam)
+	page, err := f.GetAllChildFoldersWithOptions(orgID, "alpha", folder.ChildListOptions{PageSize: 2})
+	checkPermission(t, "GetAllChildFoldersWithOptions()", err, nil)
+	if !reflect.DeepEqual(page.Folders, exampleFolders[1:3]) {
+		t.Errorf("GetAllChildFoldersWithOptions() = %v, want %v", page.Folders, exampleFolders[1:3])
+	}
+	if cursor, _ := folder.DecodeCursor(page.NextCursor); cursor != "alpha.bravo.charlie" {
+		t.Errorf("GetAllChildFoldersWithOptions() cursor = %q, want %q", cursor, "alpha.bravo.charlie")
+	}
+
+	page, err = f.GetAllChildFoldersWithOptions(orgID, "alpha", folder.ChildListOptions{PageSize: 2, Cursor: page.NextCursor})
+	checkPermission(t, "GetAllChildFoldersWithOptions()", err, nil)
+	if !reflect.DeepEqual(page.Folders, exampleFolders[3:5]) || page.NextCursor != "" {
+		t.Errorf("GetAllChildFoldersWithOptions() = %v, %q, want %v and no cursor", page.Folders, page.NextCursor, exampleFolders[3:5])
+	}
+}
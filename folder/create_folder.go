@@ -0,0 +1,60 @@
+package folder
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// CreateFolder adds a folder with 'name' below the folder
+// with 'parent', or as a root folder if 'parent' is empty,
+// creating the organization if it has no folders yet. The
+// folder is given a new ID and stamped as created now, and its
+// name is checked and normalized by the NameValidator, and
+// against its siblings by the SiblingPolicy. A folder which
+// would have the path of another is a NameConflictError
+// whatever the policy.
+func (f *driver) CreateFolder(orgID uuid.UUID, name string, parent string) (Folder, error) {
+	name, err := f.validateName(name)
+	if err != nil {
+		return Folder{}, err
+	}
+
+	org, exists := f.orgs[orgID]
+	if !exists {
+		if parent != "" {
+			return Folder{}, &NotFoundError{Kind: KindOrganization, OrgID: orgID}
+		}
+		org = NewOrg()
+		org.index = NewFolderIndex(org.folders)
+	}
+
+	var parentNode *FileNode
+	paths := f.codec.Append("", name)
+	if parent != "" {
+		parentNode = lookupNode(org, f.normalizeRef(parent))
+		if parentNode == nil {
+			return Folder{}, &NotFoundError{Kind: KindParent, OrgID: orgID, Ref: parent}
+		}
+		paths = f.codec.Append(parentNode.file.Paths, name)
+	}
+	siblings := FindRootNodes(org.folders)
+	if parentNode != nil {
+		siblings = parentNode.children
+	}
+	if err := f.checkSiblings(orgID, siblings, name, nil); err != nil {
+		return Folder{}, err
+	}
+	if existing := org.index.Lookup(paths); existing != nil {
+		return Folder{}, &NameConflictError{OrgID: orgID, Name: name, Existing: existing.file.Paths}
+	}
+	if err := f.checkCreateLimits(orgID, org, parentNode, paths); err != nil {
+		return Folder{}, err
+	}
+
+	fileNode, _ := f.addFolder(orgID, &org, parentNode, name)
+	if f.incrementalStats {
+		AddDescendants(parentNode, 1)
+	}
+	f.orgs[orgID] = org
+
+	return fileNode.file.clone(), nil
+}
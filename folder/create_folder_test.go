@@ -0,0 +1,119 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_CreateFolder(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	newOrgID := uuid.FromStringOrNil("7d3b1c5e-2a4f-4b6d-8e9f-1a2b3c4d5e6f")
+	tests := [...]struct {
+		name   string
+		orgID  uuid.UUID
+		folder string
+		parent string
+		want   string
+		err    error
+	}{
+		{
+			name:   "Below a folder named by path",
+			orgID:  orgID,
+			folder: "hotel",
+			parent: "alpha.delta",
+			want:   "alpha.delta.hotel",
+		},
+		{
+			name:   "Below a folder named by name",
+			orgID:  orgID,
+			folder: "hotel",
+			parent: "golf",
+			want:   "golf.hotel",
+		},
+		{
+			name:   "Root folder",
+			orgID:  orgID,
+			folder: "hotel",
+			want:   "hotel",
+		},
+		{
+			name:   "Root folder of a new organization",
+			orgID:  newOrgID,
+			folder: "hotel",
+			want:   "hotel",
+		},
+		{
+			name:   "Folder already exists",
+			orgID:  orgID,
+			folder: "delta",
+			parent: "alpha",
+			err:    errors.New(`error: folder name "delta" conflicts with alpha.delta`),
+		},
+		{
+			name:   "Invalid name",
+			orgID:  orgID,
+			folder: "hotel.india",
+			parent: "alpha",
+			err:    errors.New(`error: invalid folder name "hotel.india", as it contains '.'`),
+		},
+		{
+			name:   "Invalid parent",
+			orgID:  orgID,
+			folder: "hotel",
+			parent: "alpha.golf",
+			err:    errors.New("error: parent folder does not exist"),
+		},
+		{
+			name:   "Invalid UUID",
+			orgID:  newOrgID,
+			folder: "hotel",
+			parent: "alpha",
+			err:    errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...),
+				folder.WithIncrementalStats(), folder.WithClock(func() time.Time { return movedAt }))
+			get, err := f.CreateFolder(tt.orgID, tt.folder, tt.parent)
+
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("CreateFolder() = %v, want %v for error", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreateFolder() = %v, want nil for error", err)
+			}
+			if get.Paths != tt.want || get.ID == uuid.Nil || !get.CreatedAt.Equal(movedAt) || !get.UpdatedAt.Equal(movedAt) {
+				t.Errorf("CreateFolder() = %v, want a new folder at %s", get, tt.want)
+			}
+
+			// The folder can be looked up by path, name and ID
+			for _, ref := range []string{get.Paths, get.Name, get.ID.String()} {
+				stats, err := f.Stats(tt.orgID, ref)
+				if err != nil || stats.Folders != 1 {
+					t.Errorf("Stats(%s) = %v, %v after creating, want the new folder", ref, stats, err)
+				}
+			}
+			if tt.parent != "" {
+				children, _ := f.GetImmediateChildren(tt.orgID, tt.parent)
+				if !reflect.DeepEqual(children[len(children)-1], get) {
+					t.Errorf("GetImmediateChildren() = %v, want it to end with %v", children, get)
+				}
+				count, _ := f.DescendantCount(tt.orgID, "alpha")
+				if want := 4; tt.parent == "alpha.delta" && count != want+1 {
+					t.Errorf("DescendantCount() = %d after creating, want %d", count, want+1)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package folder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// ChangeKind is how a folder differs between two sets
+type ChangeKind string
+
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeMoved   ChangeKind = "moved"
+	ChangeRenamed ChangeKind = "renamed"
+)
+
+// FolderChange is a single folder which differs
+// between two sets of folders
+type FolderChange struct {
+	Kind  ChangeKind `json:"kind"`
+	OrgId uuid.UUID  `json:"org_id"`
+	// ID is the ID of the folder, if it has one
+	ID uuid.UUID `json:"id,omitempty"`
+	// Name is the name of the folder after the change,
+	// or before it if the folder was removed
+	Name string `json:"name"`
+	// OldName is the name before a rename
+	OldName  string `json:"old_name,omitempty"`
+	OldPaths string `json:"old_paths,omitempty"`
+	NewPaths string `json:"new_paths,omitempty"`
+	// Inferred is set on a rename of a folder without an ID,
+	// which was guessed from a removed and an added folder
+	Inferred bool `json:"inferred,omitempty"`
+}
+
+// DiffOptions changes how DiffWithOptions matches folders
+type DiffOptions struct {
+	// InferRenames reports a removed folder and an added
+	// folder without IDs with the same parent as a rename,
+	// shallowest folders first. The rename is a guess, so
+	// it is marked as Inferred.
+	InferRenames bool
+}
+
+// FolderDiff is every change between two sets of folders,
+// sorted by organization and then by path
+type FolderDiff struct {
+	Changes []FolderChange `json:"changes"`
+}
+
+// folderKey identifies a folder within a set of folders,
+// by its ID if it has one and by its name otherwise. A folder
+// without an ID whose name is repeated in its organization
+// is identified by its path as well.
+type folderKey struct {
+	orgID uuid.UUID
+	id    uuid.UUID
+	name  string
+	paths string
+}
+
+// pathKey is a path within an organization
+type pathKey struct {
+	orgID uuid.UUID
+	paths string
+}
+
+// keyer gives a folder the same key in each of the
+// sets of folders it was made from
+type keyer struct {
+	// repeated is the name of every folder without an ID
+	// which shares it with another in one of the sets
+	repeated map[folderKey]bool
+}
+
+// newKeyer returns the keyer for the folders in 'sets'
+func newKeyer(sets ...[]Folder) keyer {
+	k := keyer{repeated: map[folderKey]bool{}}
+	for _, folders := range sets {
+		seen := map[folderKey]bool{}
+		for _, f := range folders {
+			if f.ID != uuid.Nil {
+				continue
+			}
+			key := folderKey{orgID: f.OrgId, name: f.Name}
+			if seen[key] {
+				k.repeated[key] = true
+			}
+			seen[key] = true
+		}
+	}
+
+	return k
+}
+
+// keyOf returns the key of 'f'
+func (k keyer) keyOf(f Folder) folderKey {
+	if f.ID != uuid.Nil {
+		return folderKey{orgID: f.OrgId, id: f.ID}
+	}
+	return k.withoutID(f)
+}
+
+// withoutID returns the key 'f' would have without an ID
+func (k keyer) withoutID(f Folder) folderKey {
+	key := folderKey{orgID: f.OrgId, name: f.Name}
+	if k.repeated[key] {
+		key.paths = f.Paths
+	}
+
+	return key
+}
+
+// parentKey returns the key of the parent of 'f' in a set
+// whose keys are 'byPath', or the key a folder at the path of
+// the parent would have if there is none, and false for a
+// root folder
+func (k keyer) parentKey(byPath map[pathKey]folderKey, f Folder) (folderKey, bool) {
+	sections := SplitPath(f.Paths)
+	if len(sections) < 2 {
+		return folderKey{}, false
+	}
+
+	paths := JoinPath(sections[:len(sections)-1])
+	if key, exists := byPath[pathKey{orgID: f.OrgId, paths: paths}]; exists {
+		return key, true
+	}
+
+	return k.withoutID(Folder{Name: sections[len(sections)-2], OrgId: f.OrgId, Paths: paths}), true
+}
+
+// indexFolders returns the Folders in 'folders' by their
+// key, and the keys in the order they first appear. Only
+// the first of several folders with the same key, which
+// have the same ID or the same path, is kept.
+func (k keyer) indexFolders(folders []Folder) (map[folderKey]Folder, []folderKey) {
+	byKey := map[folderKey]Folder{}
+	keys := []folderKey{}
+	for _, f := range folders {
+		key := k.keyOf(f)
+		if _, exists := byKey[key]; !exists {
+			byKey[key] = f
+			keys = append(keys, key)
+		}
+	}
+
+	return byKey, keys
+}
+
+// indexPaths returns the key of the first of the folders
+// with 'keys' at each path
+func indexPaths(byKey map[folderKey]Folder, keys []folderKey) map[pathKey]folderKey {
+	byPath := map[pathKey]folderKey{}
+	for _, key := range keys {
+		f := byKey[key]
+		if _, exists := byPath[pathKey{orgID: f.OrgId, paths: f.Paths}]; !exists {
+			byPath[pathKey{orgID: f.OrgId, paths: f.Paths}] = key
+		}
+	}
+
+	return byPath
+}
+
+// matchFolders indexes 'before' and 'after' so the same folder
+// has the same key in both. A folder which only has an ID on
+// one side is matched by the key it would have without one,
+// if that key is not taken by a folder which was already matched.
+func (k keyer) matchFolders(before []Folder, after []Folder) (map[folderKey]Folder, []folderKey, map[folderKey]Folder, []folderKey) {
+	beforeByKey, beforeKeys := k.indexFolders(before)
+	afterByKey, afterKeys := k.indexFolders(after)
+
+	unmatched := map[folderKey]folderKey{}
+	for _, key := range beforeKeys {
+		if _, exists := afterByKey[key]; !exists {
+			plainKey := k.withoutID(beforeByKey[key])
+			if _, exists := unmatched[plainKey]; !exists {
+				unmatched[plainKey] = key
+			}
+		}
+	}
+
+	for i, key := range afterKeys {
+		if _, exists := beforeByKey[key]; exists {
+			continue
+		}
+		a := afterByKey[key]
+		plainKey := k.withoutID(a)
+		beforeKey, exists := unmatched[plainKey]
+		if !exists || (a.ID != uuid.Nil && beforeByKey[beforeKey].ID != uuid.Nil) {
+			continue
+		}
+		if _, exists := afterByKey[beforeKey]; exists {
+			continue
+		}
+		delete(unmatched, plainKey)
+		delete(afterByKey, key)
+		afterByKey[beforeKey] = a
+		afterKeys[i] = beforeKey
+	}
+
+	return beforeByKey, beforeKeys, afterByKey, afterKeys
+}
+
+// Diff returns the changes turning 'before' into 'after'.
+// Folders are matched by organization and ID when both sides
+// have one, and by organization and name otherwise, or by
+// name and path when folders without IDs share the name. A
+// folder with the same ID under a different name is renamed.
+// A folder is moved if its parent changed, so the folders
+// carried along with it are not reported. Parents are matched
+// in the same way, so a move between parents with the same
+// name is reported. A folder without an ID which changed its
+// name is removed and added.
+func Diff(before []Folder, after []Folder) FolderDiff {
+	return DiffWithOptions(before, after, DiffOptions{})
+}
+
+// DiffWithOptions returns the changes turning 'before' into
+// 'after' in the same way as Diff, changed by 'opts'
+func DiffWithOptions(before []Folder, after []Folder, opts DiffOptions) FolderDiff {
+	return diffFolders(newKeyer(before, after), before, after, opts).diff
+}
+
+// diffResult is the diff between two sets of folders,
+// along with the keys the folders were matched by
+type diffResult struct {
+	diff FolderDiff
+	// keys is the key of the folder each change applies to,
+	// as it was before the change, in the order of the changes
+	keys []folderKey
+	// after is the folders of the second set by their key,
+	// afterKeys is their keys in order, and afterPaths is
+	// the key of the first folder at each path
+	after      map[folderKey]Folder
+	afterKeys  []folderKey
+	afterPaths map[pathKey]folderKey
+	// renamedFrom maps the key of a folder whose rename
+	// was inferred back to its key in the first set
+	renamedFrom map[folderKey]folderKey
+}
+
+// keyedChange is a change with the key of its folder
+type keyedChange struct {
+	key    folderKey
+	change FolderChange
+}
+
+// diffFolders returns the diff from 'before' to 'after',
+// matching folders by the keys given by 'k'
+func diffFolders(k keyer, before []Folder, after []Folder, opts DiffOptions) diffResult {
+	beforeByKey, beforeKeys, afterByKey, afterKeys := k.matchFolders(before, after)
+
+	removed := []folderKey{}
+	for _, key := range beforeKeys {
+		if _, exists := afterByKey[key]; !exists {
+			removed = append(removed, key)
+		}
+	}
+	added := []folderKey{}
+	for _, key := range afterKeys {
+		if _, exists := beforeByKey[key]; !exists {
+			added = append(added, key)
+		}
+	}
+
+	beforePaths := indexPaths(beforeByKey, beforeKeys)
+	afterPaths := indexPaths(afterByKey, afterKeys)
+	// renamedFrom maps the key of a folder whose rename was
+	// inferred back to its old key, so the parents of folders
+	// below it can be compared
+	renamedFrom := map[folderKey]folderKey{}
+	sameParent := func(b Folder, a Folder) bool {
+		beforeParent, _ := k.parentKey(beforePaths, b)
+		afterParent, _ := k.parentKey(afterPaths, a)
+		if renamed, exists := renamedFrom[afterParent]; exists {
+			afterParent = renamed
+		}
+		return beforeParent == afterParent
+	}
+
+	changes := []keyedChange{}
+	for _, key := range beforeKeys {
+		a, exists := afterByKey[key]
+		if b := beforeByKey[key]; exists && a.Name != b.Name {
+			changes = append(changes, keyedChange{key: key, change: FolderChange{
+				Kind:     ChangeRenamed,
+				OrgId:    a.OrgId,
+				ID:       key.id,
+				Name:     a.Name,
+				OldName:  b.Name,
+				OldPaths: b.Paths,
+				NewPaths: a.Paths,
+			}})
+		}
+	}
+
+	slices.SortStableFunc(removed, func(a folderKey, b folderKey) int {
+		return ComparePaths(SplitPath(beforeByKey[a].Paths), SplitPath(beforeByKey[b].Paths))
+	})
+	paired := map[folderKey]bool{}
+	for _, key := range removed {
+		r := beforeByKey[key]
+		match := -1
+		if key.id == uuid.Nil && opts.InferRenames {
+			match = slices.IndexFunc(added, func(addedKey folderKey) bool {
+				a := afterByKey[addedKey]
+				return addedKey.id == uuid.Nil && a.OrgId == r.OrgId &&
+					sameParent(r, a) && !paired[addedKey]
+			})
+		}
+		if match < 0 {
+			changes = append(changes, keyedChange{key: key, change: FolderChange{
+				Kind:     ChangeRemoved,
+				OrgId:    r.OrgId,
+				ID:       key.id,
+				Name:     r.Name,
+				OldPaths: r.Paths,
+			}})
+			continue
+		}
+
+		a := afterByKey[added[match]]
+		paired[added[match]] = true
+		renamedFrom[added[match]] = key
+		changes = append(changes, keyedChange{key: key, change: FolderChange{
+			Kind:     ChangeRenamed,
+			OrgId:    a.OrgId,
+			Name:     a.Name,
+			OldName:  r.Name,
+			OldPaths: r.Paths,
+			NewPaths: a.Paths,
+			Inferred: true,
+		}})
+	}
+
+	for _, key := range added {
+		if a := afterByKey[key]; !paired[key] {
+			changes = append(changes, keyedChange{key: key, change: FolderChange{
+				Kind:     ChangeAdded,
+				OrgId:    a.OrgId,
+				ID:       key.id,
+				Name:     a.Name,
+				NewPaths: a.Paths,
+			}})
+		}
+	}
+
+	for _, key := range beforeKeys {
+		a, exists := afterByKey[key]
+		if !exists {
+			continue
+		}
+		if b := beforeByKey[key]; !sameParent(b, a) {
+			changes = append(changes, keyedChange{key: key, change: FolderChange{
+				Kind:     ChangeMoved,
+				OrgId:    a.OrgId,
+				ID:       key.id,
+				Name:     a.Name,
+				OldPaths: b.Paths,
+				NewPaths: a.Paths,
+			}})
+		}
+	}
+
+	slices.SortStableFunc(changes, func(a keyedChange, b keyedChange) int {
+		return compareChanges(a.change, b.change)
+	})
+	result := diffResult{
+		diff:        FolderDiff{Changes: []FolderChange{}},
+		keys:        []folderKey{},
+		after:       afterByKey,
+		afterKeys:   afterKeys,
+		afterPaths:  afterPaths,
+		renamedFrom: renamedFrom,
+	}
+	for _, c := range changes {
+		result.diff.Changes = append(result.diff.Changes, c.change)
+		result.keys = append(result.keys, c.key)
+	}
+
+	return result
+}
+
+// changePaths returns the path a change is sorted by
+func changePaths(c FolderChange) string {
+	if c.Kind == ChangeRemoved {
+		return c.OldPaths
+	}
+	return c.NewPaths
+}
+
+func compareChanges(a FolderChange, b FolderChange) int {
+	if c := strings.Compare(a.OrgId.String(), b.OrgId.String()); c != 0 {
+		return c
+	}
+	if c := ComparePaths(SplitPath(changePaths(a)), SplitPath(changePaths(b))); c != 0 {
+		return c
+	}
+	return strings.Compare(string(a.Kind), string(b.Kind))
+}
+
+// IsEmpty returns whether there are no changes
+func (d FolderDiff) IsEmpty() bool {
+	return len(d.Changes) == 0
+}
+
+// String returns the diff as text, with one change per line
+func (d FolderDiff) String() string {
+	sb := strings.Builder{}
+	for _, c := range d.Changes {
+		switch c.Kind {
+		case ChangeAdded:
+			fmt.Fprintf(&sb, "%-8s %s %s\n", c.Kind, c.OrgId, c.NewPaths)
+		case ChangeRemoved:
+			fmt.Fprintf(&sb, "%-8s %s %s\n", c.Kind, c.OrgId, c.OldPaths)
+		default:
+			fmt.Fprintf(&sb, "%-8s %s %s -> %s", c.Kind, c.OrgId, c.OldPaths, c.NewPaths)
+			if c.Inferred {
+				sb.WriteString(" (inferred)")
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// JSON returns the diff as indented JSON
+func (d FolderDiff) JSON() []byte {
+	return MarshalJson(d)
+}
@@ -0,0 +1,327 @@
+package folder_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// renamedFolders is exampleFolders with alpha.bravo
+// renamed to alpha.brave
+var renamedFolders = []folder.Folder{
+	{Name: "alpha", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha"},
+	{Name: "brave", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha.brave"},
+	{Name: "charlie", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha.brave.charlie"},
+	{Name: "delta", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha.delta"},
+	{Name: "echo", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha.delta.echo"},
+	exampleFolders[5],
+	{Name: "golf", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "golf"},
+}
+
+func Test_folder_Diff(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	otherOrgID := uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7")
+	charlieID := uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a06")
+	repeatedFolders := []folder.Folder{
+		{Name: "a", OrgId: orgID, Paths: "a"},
+		{Name: "b", OrgId: orgID, Paths: "b"},
+		{Name: "x", OrgId: orgID, Paths: "a.x"},
+		{Name: "x", OrgId: orgID, Paths: "b.x"},
+	}
+	tests := [...]struct {
+		name   string
+		before []folder.Folder
+		after  []folder.Folder
+		opts   folder.DiffOptions
+		want   []folder.FolderChange
+	}{
+		{
+			name:   "No changes",
+			before: exampleFolders,
+			after:  exampleFolders,
+			want:   []folder.FolderChange{},
+		},
+		{
+			name:   "Moved subtree only reports its root",
+			before: exampleFolders,
+			after: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "golf.bravo"},
+				{Name: "charlie", OrgId: orgID, Paths: "golf.bravo.charlie"},
+				{Name: "delta", OrgId: orgID, Paths: "alpha.delta"},
+				{Name: "echo", OrgId: orgID, Paths: "alpha.delta.echo"},
+				{Name: "foxtrot", OrgId: otherOrgID, Paths: "foxtrot"},
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+			},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeMoved,
+					OrgId:    orgID,
+					Name:     "bravo",
+					OldPaths: "alpha.bravo",
+					NewPaths: "golf.bravo",
+				},
+			},
+		},
+		{
+			name:   "Renamed folder without an ID",
+			before: exampleFolders,
+			after:  renamedFolders,
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeAdded,
+					OrgId:    orgID,
+					Name:     "brave",
+					NewPaths: "alpha.brave",
+				},
+				{
+					Kind:     folder.ChangeMoved,
+					OrgId:    orgID,
+					Name:     "charlie",
+					OldPaths: "alpha.bravo.charlie",
+					NewPaths: "alpha.brave.charlie",
+				},
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					Name:     "bravo",
+					OldPaths: "alpha.bravo",
+				},
+			},
+		},
+		{
+			name:   "Inferred rename keeps its children",
+			before: exampleFolders,
+			after:  renamedFolders,
+			opts:   folder.DiffOptions{InferRenames: true},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeRenamed,
+					OrgId:    orgID,
+					Name:     "brave",
+					OldName:  "bravo",
+					OldPaths: "alpha.bravo",
+					NewPaths: "alpha.brave",
+					Inferred: true,
+				},
+			},
+		},
+		{
+			name:   "Added and removed folders",
+			before: exampleFolders,
+			after: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+				{Name: "charlie", OrgId: orgID, Paths: "alpha.bravo.charlie"},
+				{Name: "foxtrot", OrgId: otherOrgID, Paths: "foxtrot"},
+				{Name: "hotel", OrgId: otherOrgID, Paths: "foxtrot.hotel"},
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+			},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeAdded,
+					OrgId:    otherOrgID,
+					Name:     "hotel",
+					NewPaths: "foxtrot.hotel",
+				},
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					Name:     "delta",
+					OldPaths: "alpha.delta",
+				},
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					Name:     "echo",
+					OldPaths: "alpha.delta.echo",
+				},
+			},
+		},
+		{
+			name: "Same name in different orgs",
+			before: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+			},
+			after: []folder.Folder{
+				{Name: "alpha", OrgId: otherOrgID, Paths: "alpha"},
+			},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeAdded,
+					OrgId:    otherOrgID,
+					Name:     "alpha",
+					NewPaths: "alpha",
+				},
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					Name:     "alpha",
+					OldPaths: "alpha",
+				},
+			},
+		},
+		{
+			name:   "Folders with IDs matched by ID",
+			before: idFolders,
+			after: []folder.Folder{
+				idFolders[0],
+				idFolders[1],
+				idFolders[2],
+				{ID: idFolders[3].ID, Name: "charlie", OrgId: orgID, Paths: "alpha.charlie"},
+			},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeMoved,
+					OrgId:    orgID,
+					ID:       idFolders[3].ID,
+					Name:     "charlie",
+					OldPaths: "golf.bravo",
+					NewPaths: "alpha.charlie",
+				},
+				{
+					Kind:     folder.ChangeRenamed,
+					OrgId:    orgID,
+					ID:       idFolders[3].ID,
+					Name:     "charlie",
+					OldName:  "bravo",
+					OldPaths: "golf.bravo",
+					NewPaths: "alpha.charlie",
+				},
+			},
+		},
+		{
+			name:   "Same name with different IDs",
+			before: idFolders[:2],
+			after: []folder.Folder{
+				idFolders[0],
+				{ID: uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a05"), Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+			},
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeAdded,
+					OrgId:    orgID,
+					ID:       uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a05"),
+					Name:     "bravo",
+					NewPaths: "alpha.bravo",
+				},
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					ID:       idFolders[1].ID,
+					Name:     "bravo",
+					OldPaths: "alpha.bravo",
+				},
+			},
+		},
+		{
+			name:   "ID on one side only matched by name",
+			before: exampleFolders[:2],
+			after:  []folder.Folder{exampleFolders[0], idFolders[1]},
+			want:   []folder.FolderChange{},
+		},
+		{
+			name: "Moved between parents with the same name and IDs",
+			before: append(append([]folder.Folder{}, idFolders...),
+				folder.Folder{ID: charlieID, Name: "charlie", OrgId: orgID, Paths: "alpha.bravo.charlie"}),
+			after: append(append([]folder.Folder{}, idFolders...),
+				folder.Folder{ID: charlieID, Name: "charlie", OrgId: orgID, Paths: "golf.bravo.charlie"}),
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeMoved,
+					OrgId:    orgID,
+					ID:       charlieID,
+					Name:     "charlie",
+					OldPaths: "alpha.bravo.charlie",
+					NewPaths: "golf.bravo.charlie",
+				},
+			},
+		},
+		{
+			name:   "Moved between parents with the same name",
+			before: append(append([]folder.Folder{}, repeatedFolders...), folder.Folder{Name: "c", OrgId: orgID, Paths: "a.x.c"}),
+			after:  append(append([]folder.Folder{}, repeatedFolders...), folder.Folder{Name: "c", OrgId: orgID, Paths: "b.x.c"}),
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeMoved,
+					OrgId:    orgID,
+					Name:     "c",
+					OldPaths: "a.x.c",
+					NewPaths: "b.x.c",
+				},
+			},
+		},
+		{
+			name:   "Removed one of several folders with the same name",
+			before: repeatedFolders,
+			after:  repeatedFolders[:3],
+			want: []folder.FolderChange{
+				{
+					Kind:     folder.ChangeRemoved,
+					OrgId:    orgID,
+					Name:     "x",
+					OldPaths: "b.x",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := folder.DiffWithOptions(tt.before, tt.after, tt.opts)
+
+			if !reflect.DeepEqual(get.Changes, tt.want) {
+				t.Errorf("DiffWithOptions() = %v, want %v", get.Changes, tt.want)
+			}
+			if get.IsEmpty() != (len(tt.want) == 0) {
+				t.Errorf("DiffWithOptions().IsEmpty() = %v, want %v", get.IsEmpty(), len(tt.want) == 0)
+			}
+		})
+	}
+}
+
+func Test_folder_Diff_Rendering(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	before := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+		{Name: "charlie", OrgId: orgID, Paths: "charlie"},
+	}
+	after := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{Name: "bravo", OrgId: orgID, Paths: "alpha.charlie.bravo"},
+		{Name: "charlie", OrgId: orgID, Paths: "alpha.charlie"},
+		{Name: "delta", OrgId: orgID, Paths: "delta"},
+	}
+	get := folder.Diff(before, after)
+
+	wantText := "moved    " + folder.DefaultOrgID + " charlie -> alpha.charlie\n" +
+		"moved    " + folder.DefaultOrgID + " alpha.bravo -> alpha.charlie.bravo\n" +
+		"added    " + folder.DefaultOrgID + " delta\n"
+	if get.String() != wantText {
+		t.Errorf("Diff().String() = %q, want %q", get.String(), wantText)
+	}
+
+	decoded := folder.FolderDiff{}
+	if err := json.Unmarshal(get.JSON(), &decoded); err != nil {
+		t.Fatalf("Diff().JSON() is not valid JSON: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, get) {
+		t.Errorf("Diff().JSON() decoded to %v, want %v", decoded, get)
+	}
+}
+
+func Test_folder_Diff_InferredRendering(t *testing.T) {
+	t.Parallel()
+	get := folder.DiffWithOptions(exampleFolders, renamedFolders, folder.DiffOptions{InferRenames: true})
+
+	want := "renamed  " + folder.DefaultOrgID + " alpha.bravo -> alpha.brave (inferred)\n"
+	if get.String() != want {
+		t.Errorf("DiffWithOptions().String() = %q, want %q", get.String(), want)
+	}
+}
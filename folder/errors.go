@@ -0,0 +1,163 @@
+package folder
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// ErrNotFound is matched by every NotFoundError,
+// so callers can check for it using errors.Is
+var ErrNotFound = errors.New("error: not found")
+
+// The kinds of things a NotFoundError can refer to
+const (
+	KindOrganization      = "organization"
+	KindFolder            = "folder"
+	KindParent            = "parent folder"
+	KindSourceFolder      = "source folder"
+	KindDestinationFolder = "destination folder"
+	KindCommonAncestor    = "common ancestor"
+	KindGrantedFolder     = "granted folder"
+)
+
+// NotFoundError is returned when an organization or
+// folder that was asked for does not exist
+type NotFoundError struct {
+	// Kind is what could not be found, such as KindFolder
+	Kind  string
+	OrgID uuid.UUID
+	// Ref is the path or name that was looked up
+	Ref string
+	// Suggestions are names similar to Ref that do exist
+	Suggestions []string
+}
+
+func (e *NotFoundError) Error() string {
+	msg := fmt.Sprintf("error: %s does not exist", e.Kind)
+	if len(e.Suggestions) == 0 {
+		return msg
+	}
+
+	quoted := []string{}
+	for _, s := range e.Suggestions {
+		quoted = append(quoted, strconv.Quote(s))
+	}
+	last := len(quoted) - 1
+	if last == 0 {
+		return fmt.Sprintf("%s, did you mean %s?", msg, quoted[0])
+	}
+	return fmt.Sprintf("%s, did you mean %s or %s?", msg, strings.Join(quoted[:last], ", "), quoted[last])
+}
+
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// ErrPermissionDenied is matched by every PermissionError,
+// so callers can check for it using errors.Is
+var ErrPermissionDenied = errors.New("error: permission denied")
+
+// PermissionError is returned when a principal does not
+// have the role needed for an operation
+type PermissionError struct {
+	// Kind is what the role was needed on, such as KindFolder
+	Kind      string
+	OrgID     uuid.UUID
+	Principal string
+	// Ref is the path or name that was looked up
+	Ref string
+	// Need is the role that was needed
+	Need Role
+}
+
+func (e *PermissionError) Error() string {
+	return fmt.Sprintf("error: permission denied to %s %s", e.Need.verb(), e.Kind)
+}
+
+func (e *PermissionError) Is(target error) bool {
+	return target == ErrPermissionDenied
+}
+
+// ErrLimitExceeded is matched by every LimitError,
+// so callers can check for it using errors.Is
+var ErrLimitExceeded = errors.New("error: limit exceeded")
+
+// The limits of an OrgPolicy a LimitError can refer to
+const (
+	LimitDepth      = "depth"
+	LimitChildren   = "children"
+	LimitFolders    = "folders"
+	LimitPathLength = "path length"
+)
+
+// LimitError is returned when an operation would break
+// the OrgPolicy of an organization
+type LimitError struct {
+	// Limit is which limit would be broken, such as LimitDepth
+	Limit string
+	OrgID uuid.UUID
+	// Ref is the path of the folder the limit applies to
+	Ref string
+	// Max is the limit, and Value what the operation would
+	// have made it
+	Max   int
+	Value int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("error: %s of %d is over the limit of %d", e.Limit, e.Value, e.Max)
+}
+
+func (e *LimitError) Is(target error) bool {
+	return target == ErrLimitExceeded
+}
+
+// ErrInvalidName is matched by every NameError,
+// so callers can check for it using errors.Is
+var ErrInvalidName = errors.New("error: invalid folder name")
+
+// NameError is returned when a folder name is not
+// allowed by the NameValidator of a driver
+type NameError struct {
+	Name string
+	// Reason is why the name is not allowed
+	Reason string
+}
+
+func (e *NameError) Error() string {
+	return fmt.Sprintf("error: invalid folder name %q, as %s", e.Name, e.Reason)
+}
+
+func (e *NameError) Is(target error) bool {
+	return target == ErrInvalidName
+}
+
+// ErrNameConflict is matched by every NameConflictError,
+// so callers can check for it using errors.Is
+var ErrNameConflict = errors.New("error: folder name conflict")
+
+// NameConflictError is returned when a folder would have
+// the same name as a sibling, under the SiblingPolicy of
+// the organization
+type NameConflictError struct {
+	OrgID uuid.UUID
+	Name  string
+	// Existing is the path of the sibling with the same name,
+	// or empty if the caller may not see it
+	Existing string
+}
+
+func (e *NameConflictError) Error() string {
+	if e.Existing == "" {
+		return fmt.Sprintf("error: folder name %q conflicts with a sibling", e.Name)
+	}
+	return fmt.Sprintf("error: folder name %q conflicts with %s", e.Name, e.Existing)
+}
+
+func (e *NameConflictError) Is(target error) bool {
+	return target == ErrNameConflict
+}
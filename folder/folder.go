@@ -1,7 +1,8 @@
 package folder
 
 import (
-	"strings"
+	"iter"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -10,15 +11,23 @@ type FileNode struct {
 	file     Folder
 	parent   *FileNode
 	children []*FileNode
+
+	// descendants is the number of FileNodes below this one,
+	// only kept up to date by drivers with incremental stats
+	descendants int
 }
 
 type Organization struct {
 	folders []*FileNode
+	// index is built once the folders have been linked
+	index *FolderIndex
 }
 
 type IDriver interface {
 	// GetFoldersByOrgID returns all folders that belong to a specific orgID.
 	GetFoldersByOrgID(orgID uuid.UUID) []Folder
+	// GetFoldersByOrgIDWithFilter returns the folders of an orgID matching a filter.
+	GetFoldersByOrgIDWithFilter(orgID uuid.UUID, filter FolderFilter) []Folder
 	// component 1
 	// Implement the following methods:
 	// GetAllChildFolders returns all child folders of a specific folder.
@@ -28,16 +37,167 @@ type IDriver interface {
 	// Implement the following methods:
 	// MoveFolder moves a folder to a new destination.
 	MoveFolder(name string, dst string) ([]Folder, error)
+	// CreateFolder adds a new folder, below a parent or as a root folder.
+	CreateFolder(orgID uuid.UUID, name string, parent string) (Folder, error)
+	// DryRunMove returns the path changes MoveFolder would make, without moving.
+	DryRunMove(orgID uuid.UUID, src string, dst string) ([]MoveChange, error)
+
+	// Stats returns counts describing the subtree of a folder.
+	Stats(orgID uuid.UUID, path string) (FolderStats, error)
+	// OrgStats returns counts describing all folders of an organization.
+	OrgStats(orgID uuid.UUID) (FolderStats, error)
+	// DescendantCount returns the number of folders below a folder.
+	DescendantCount(orgID uuid.UUID, path string) (int, error)
+
+	// GetAncestors returns the folders above a folder, from its root down.
+	GetAncestors(orgID uuid.UUID, path string) ([]Folder, error)
+	// GetParent returns the folder immediately above a folder.
+	GetParent(orgID uuid.UUID, path string) (Folder, error)
+	// GetSiblings returns the other folders sharing a folder's parent.
+	GetSiblings(orgID uuid.UUID, path string) ([]Folder, error)
+	// GetImmediateChildren returns the folders immediately below a folder.
+	GetImmediateChildren(orgID uuid.UUID, path string) ([]Folder, error)
+
+	// GetAllChildFoldersWithOptions returns one page of child folders in path order.
+	GetAllChildFoldersWithOptions(orgID uuid.UUID, name string, opts ChildListOptions) (ChildPage, error)
+	// ChildFolders returns an iterator over the child folders of a folder.
+	ChildFolders(orgID uuid.UUID, name string, maxDepth int) (iter.Seq[Folder], error)
+	// Walk returns an iterator over a subtree, or a whole organization.
+	Walk(orgID uuid.UUID, path string, order WalkOrder) (iter.Seq[Visit], error)
+
+	// LowestCommonAncestor returns the deepest folder above or at both folders.
+	LowestCommonAncestor(orgID uuid.UUID, a string, b string) (Folder, error)
+	// Distance returns the number of parent links between two folders.
+	Distance(orgID uuid.UUID, a string, b string) (int, error)
+	// RelativePath returns how to get from one folder to another.
+	RelativePath(orgID uuid.UUID, a string, b string) (PathDiff, error)
+
+	// Search returns the folders of an organization whose names match a query.
+	Search(orgID uuid.UUID, query string, opts SearchOptions) ([]Folder, error)
+
+	// LoadReport returns the folders loaded without their parents, and what was done with them.
+	LoadReport() LoadReport
+	// CheckInvariants returns every way the folders of an organization are inconsistent.
+	CheckInvariants(orgID uuid.UUID) []Violation
+}
+
+// DriverOption changes how a driver returned by
+// NewDriver behaves
+type DriverOption func(*driver)
+
+// WithIncrementalStats makes the driver keep a count of the
+// descendants of every folder, updated by MoveFolder, so that
+// DescendantCount does not need to walk the subtree
+func WithIncrementalStats() DriverOption {
+	return func(d *driver) {
+		d.incrementalStats = true
+	}
+}
+
+// WithPathCodec makes the driver read and write the Paths
+// of folders using 'codec' in place of DefaultPathCodec, such
+// as SlashPathCodec for filesystem style paths
+func WithPathCodec(codec PathCodec) DriverOption {
+	return func(d *driver) {
+		d.codec = codec
+	}
+}
+
+// WithClock makes the driver stamp folders with the times
+// returned by 'now' rather than the current time
+func WithClock(now func() time.Time) DriverOption {
+	return func(d *driver) {
+		d.now = now
+	}
+}
+
+// NewDriver returns an IDriver for 'folders', in the same way
+// as LoadDriver, except that a folder with a name which is not
+// allowed is loaded as it is given. Names such as "v1.2 release"
+// are not allowed by the DefaultNameValidator, so CreateFolder
+// rejects them unless WithNameValidator(PermissiveNameValidator)
+// is given. NewDriver only panics if the OrphanPolicy is
+// OrphanReject and a folder is missing its parent. Paths are
+// split into names using the PathCodec of the driver, the
+// DefaultPathCodec unless WithPathCodec is given.
+func NewDriver(folders []Folder, opts ...DriverOption) IDriver {
+	d, err := loadDriver(folders, opts, false)
+	if err != nil {
+		panic(err)
+	}
+
+	return d
+}
+
+// LoadDriver returns an IDriver for 'folders', with every name
+// in them checked and normalized by the NameValidator of the
+// driver, or a RecordError for the first folder with a name
+// that is not allowed. Folders whose parents are missing are
+// handled by the OrphanPolicy of the driver.
+func LoadDriver(folders []Folder, opts ...DriverOption) (IDriver, error) {
+	d, err := loadDriver(folders, opts, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// loadDriver returns a driver for 'folders' with 'opts'
+// applied. A folder with a name which is not allowed is a
+// RecordError if 'strict' is set, and is loaded as it is
+// given otherwise.
+func loadDriver(folders []Folder, opts []DriverOption, strict bool) (*driver, error) {
+	d := applyOptions(opts)
+
+	orgs := map[uuid.UUID]Organization{}
+	for i, f := range folders {
+		normalized, err := normalizeFolder(f, d.validateName, d.codec)
+		if err == nil {
+			f = normalized
+		} else if strict {
+			return nil, &RecordError{Index: i, Err: err}
+		}
+		AddFileNode(f, orgs)
+	}
+	LinkOrgs(orgs, d.codec)
+	if err := d.adoptOrphans(orgs); err != nil {
+		return nil, err
+	}
+	d.setOrgs(orgs)
+
+	return d, nil
 }
 
-// ASSUMPTION: no folder names in 'folders' contain the
-// character '.', and this character is only used to
-// separate the path of a file
-func NewDriver(folders []Folder) IDriver {
-	orgs := GenerateOrgs(folders)
+// applyOptions returns a driver with no organizations,
+// and 'opts' applied
+func applyOptions(opts []DriverOption) *driver {
+	d := &driver{
+		orgs:         map[uuid.UUID]Organization{},
+		now:          time.Now,
+		validateName: DefaultNameValidator,
+		codec:        DefaultPathCodec,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
 
-	return &driver{
-		orgs: orgs,
+// setOrgs gives the driver 'orgs', which have
+// already been linked
+func (d *driver) setOrgs(orgs map[uuid.UUID]Organization) {
+	d.orgs = orgs
+
+	if d.incrementalStats {
+		for _, org := range orgs {
+			for _, fileNode := range org.folders {
+				if fileNode.parent == nil {
+					CountDescendants(fileNode)
+				}
+			}
+		}
 	}
 }
 
@@ -46,7 +206,7 @@ func NewDriver(folders []Folder) IDriver {
 // and child
 func NewFileNode(folder Folder) *FileNode {
 	return &FileNode{
-		file:     folder,
+		file:     folder.clone(),
 		parent:   nil,
 		children: []*FileNode{},
 	}
@@ -61,6 +221,23 @@ func NewOrg() Organization {
 
 type driver struct {
 	orgs map[uuid.UUID]Organization
+
+	incrementalStats bool
+	// now returns the time folders are stamped with
+	now func() time.Time
+	// policies are the OrgPolicies of organizations which
+	// do not use the default policy
+	policies      map[uuid.UUID]OrgPolicy
+	defaultPolicy OrgPolicy
+	// validateName checks and normalizes the name of
+	// every folder loaded or created
+	validateName NameValidator
+	// codec writes and splits the Paths of every folder
+	codec PathCodec
+	// orphanPolicy is applied to the folders loaded without
+	// their parents, which are recorded in orphans
+	orphanPolicy OrphanPolicy
+	orphans      []Orphan
 }
 
 // FindFileNode returns a pointer to the FileNode with
@@ -75,38 +252,209 @@ func FindFileNode(folders []*FileNode, name string) *FileNode {
 	return nil
 }
 
+// FindFileNodeByPath returns a pointer to the FileNode
+// whose Folder has the given path, stored inside the
+// same Organization
+func FindFileNodeByPath(folders []*FileNode, path string) *FileNode {
+	for _, f := range folders {
+		if f.file.Paths == path {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// FindFileNodeByID returns a pointer to the FileNode
+// whose Folder has the given ID, stored inside the same
+// Organization
+func FindFileNodeByID(folders []*FileNode, id uuid.UUID) *FileNode {
+	for _, f := range folders {
+		if f.file.ID == id {
+			return f
+		}
+	}
+
+	return nil
+}
+
+// FindRootNodes returns the FileNodes in 'folders'
+// which have no parent
+func FindRootNodes(folders []*FileNode) []*FileNode {
+	roots := []*FileNode{}
+	for _, f := range folders {
+		if f.parent == nil {
+			roots = append(roots, f)
+		}
+	}
+
+	return roots
+}
+
+// findOrg returns the Organization with 'orgID', or a
+// NotFoundError if it does not exist
+func (f *driver) findOrg(orgID uuid.UUID) (Organization, error) {
+	org, exists := f.orgs[orgID]
+	if !exists {
+		return Organization{}, &NotFoundError{Kind: KindOrganization, OrgID: orgID}
+	}
+
+	return org, nil
+}
+
+// findNode returns the FileNode in the Organization with
+// 'orgID' whose ID is 'ref', or failing that whose path and
+// then whose name is 'ref', or a NotFoundError if there is
+// no such FileNode. Names in 'ref' are normalized as the
+// names of loaded folders are.
+func (f *driver) findNode(orgID uuid.UUID, ref string) (*FileNode, error) {
+	org, err := f.findOrg(orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileNode := lookupNode(org, f.normalizeRef(ref))
+	if fileNode == nil {
+		return nil, &NotFoundError{Kind: KindFolder, OrgID: orgID, Ref: ref}
+	}
+
+	return fileNode, nil
+}
+
+// findAnyNode returns the FileNode in any Organization whose
+// ID is 'ref', or failing that whose path is 'ref', or failing
+// that the first FileNode whose name is 'ref' as found by
+// FindFolder, or nil. Names in 'ref' are normalized as the
+// names of loaded folders are.
+func (f *driver) findAnyNode(ref string) *FileNode {
+	ref = f.normalizeRef(ref)
+	if id, err := uuid.FromString(ref); err == nil {
+		for _, org := range f.orgs {
+			if fileNode := lookupID(org, id); fileNode != nil {
+				return fileNode
+			}
+		}
+	}
+	for _, org := range f.orgs {
+		if org.index != nil {
+			if fileNode := org.index.Lookup(ref); fileNode != nil {
+				return fileNode
+			}
+		}
+	}
+
+	fileNode, _ := FindFolder(ref, f.orgs)
+	return fileNode
+}
+
+// findMoveNodes returns the FileNodes 'src' and 'dst' refer to
+// in a move, or nil for either which does not exist. With an
+// 'orgID' other than uuid.Nil the source is only looked for in
+// that Organization, and the destination is looked for there
+// first, so one in another Organization is reported as such.
+func (f *driver) findMoveNodes(orgID uuid.UUID, src string, dst string) (*FileNode, *FileNode) {
+	src, dst = f.normalizeRef(src), f.normalizeRef(dst)
+	org, exists := f.orgs[orgID]
+	if !exists {
+		return f.findAnyNode(src), f.findAnyNode(dst)
+	}
+
+	dstFolder := lookupNode(org, dst)
+	if dstFolder == nil {
+		dstFolder = f.findAnyNode(dst)
+	}
+
+	return lookupNode(org, src), dstFolder
+}
+
+// lookupNode returns the FileNode in 'org' whose ID, path
+// or name is 'ref', in that order, or nil
+func lookupNode(org Organization, ref string) *FileNode {
+	if id, err := uuid.FromString(ref); err == nil {
+		if fileNode := lookupID(org, id); fileNode != nil {
+			return fileNode
+		}
+	}
+
+	var fileNode *FileNode
+	if org.index != nil {
+		fileNode = org.index.Lookup(ref)
+	} else {
+		fileNode = FindFileNodeByPath(org.folders, ref)
+	}
+	if fileNode == nil {
+		fileNode = FindFileNode(org.folders, ref)
+	}
+
+	return fileNode
+}
+
+// lookupID returns the FileNode in 'org' whose ID is
+// 'id', or nil
+func lookupID(org Organization, id uuid.UUID) *FileNode {
+	if id == uuid.Nil {
+		return nil
+	}
+	if org.index != nil {
+		return org.index.LookupID(id)
+	}
+	return FindFileNodeByID(org.folders, id)
+}
+
 // GenerateFileNodes returns a map hashed by UUIDs, storing
 // Organizations which contains a slice of pointers to
 // their organization's respective FileNodes
 func GenerateFileNodes(folders []Folder, orgs map[uuid.UUID]Organization) {
 	for _, f := range folders {
-		_, exists := orgs[f.OrgId]
-		if !exists {
-			orgs[f.OrgId] = NewOrg()
-		}
-		org := orgs[f.OrgId]
-		org.folders = append(org.folders, NewFileNode(f))
-		orgs[f.OrgId] = org
+		AddFileNode(f, orgs)
+	}
+}
+
+// AddFileNode appends a new FileNode containing 'f' to the
+// Organization it belongs to, creating the Organization if
+// it does not exist yet
+func AddFileNode(f Folder, orgs map[uuid.UUID]Organization) {
+	_, exists := orgs[f.OrgId]
+	if !exists {
+		orgs[f.OrgId] = NewOrg()
 	}
+	org := orgs[f.OrgId]
+	org.folders = append(org.folders, NewFileNode(f))
+	orgs[f.OrgId] = org
 }
 
 // GenerateNodeParents changes the 'parent' field of
-// each folder in 'folders' to the FileNode whose file
-// name is the immediate parent of each file given in
-// their path
-func GenerateNodeParents(folders []*FileNode) {
+// each folder in 'folders' to the FileNode at the path of
+// its immediate parent, or failing that the first FileNode
+// with the name of its immediate parent, split using 'codec'
+// from its path, and adds it to the children of its parent
+func GenerateNodeParents(folders []*FileNode, codec PathCodec) {
+	byPath := map[string]*FileNode{}
+	byName := map[string]*FileNode{}
+	for _, fileNode := range folders {
+		if _, exists := byPath[fileNode.file.Paths]; !exists {
+			byPath[fileNode.file.Paths] = fileNode
+		}
+		if _, exists := byName[fileNode.file.Name]; !exists {
+			byName[fileNode.file.Name] = fileNode
+		}
+	}
+
 	for i, fileNode := range folders {
 		curr_path := fileNode.file.Paths
-		path_sections := strings.Split(curr_path, ".")
+		path_sections := codec.Split(curr_path)
 
 		if len(path_sections) <= 1 {
 			continue
 		}
 
-		// The name of the immediate parent FileNode is given by the second
+		// The immediate parent FileNode is given by the second
 		// last directory in the file's path, as the last is itself
-		parent := FindFileNode(folders, path_sections[len(path_sections)-2])
-		if parent == nil {
+		parent, exists := byPath[codec.Join(path_sections[:len(path_sections)-1])]
+		if !exists {
+			parent, exists = byName[path_sections[len(path_sections)-2]]
+		}
+		if !exists {
 			continue
 		}
 
@@ -129,17 +477,23 @@ func GenerateNodeChildren(folders []*FileNode, parentNode *FileNode) []*FileNode
 
 // GenerateOrgs returns a map of Organizations, hashed
 // by the Organization's OrgId and containing a slice of
-// pointers to all FileNodes contained in that Organization
+// pointers to all FileNodes contained in that Organization.
+// Paths are split using the DefaultPathCodec.
 func GenerateOrgs(folders []Folder) map[uuid.UUID]Organization {
 	orgs := map[uuid.UUID]Organization{}
 	GenerateFileNodes(folders, orgs)
-
-	for _, org := range orgs {
-		GenerateNodeParents(org.folders)
-		for i, fileNode := range org.folders {
-			org.folders[i].children = GenerateNodeChildren(org.folders, fileNode)
-		}
-	}
+	LinkOrgs(orgs, DefaultPathCodec)
 
 	return orgs
 }
+
+// LinkOrgs generates the parent and children links of every
+// FileNode stored in 'orgs', once all of them have been added,
+// and indexes each Organization. Paths are split using 'codec'.
+func LinkOrgs(orgs map[uuid.UUID]Organization, codec PathCodec) {
+	for orgID, org := range orgs {
+		GenerateNodeParents(org.folders, codec)
+		org.index = NewFolderIndex(org.folders)
+		orgs[orgID] = org
+	}
+}
@@ -0,0 +1,132 @@
+package folder
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// FolderIndex speeds up looking up the FileNodes of an
+// Organization by path, and searching them by name
+type FolderIndex struct {
+	byPath map[string]*FileNode
+	// byID holds the FileNodes whose Folder has an ID, which
+	// never changes when the FileNode is moved
+	byID map[uuid.UUID]*FileNode
+	// byName is sorted by lower case name, then by path
+	byName []indexEntry
+}
+
+// indexEntry is a FileNode stored under its lower case
+// name, which never changes when the FileNode is moved
+type indexEntry struct {
+	folded   string
+	fileNode *FileNode
+}
+
+// NewFolderIndex returns a FolderIndex of 'folders'. Where
+// more than one FileNode has the same path, the first one is
+// indexed, as with FindFileNodeByPath.
+func NewFolderIndex(folders []*FileNode) *FolderIndex {
+	index := &FolderIndex{
+		byPath: map[string]*FileNode{},
+		byID:   map[uuid.UUID]*FileNode{},
+		byName: make([]indexEntry, 0, len(folders)),
+	}
+
+	for _, fileNode := range folders {
+		index.addPath(fileNode)
+		index.addID(fileNode)
+		index.byName = append(index.byName, newIndexEntry(fileNode))
+	}
+	slices.SortFunc(index.byName, compareEntries)
+
+	return index
+}
+
+// Add indexes 'fileNode', which has been added to the
+// Organization after the FolderIndex was built
+func (index *FolderIndex) Add(fileNode *FileNode) {
+	index.addPath(fileNode)
+	index.addID(fileNode)
+
+	entry := newIndexEntry(fileNode)
+	i, _ := slices.BinarySearchFunc(index.byName, entry, compareEntries)
+	index.byName = slices.Insert(index.byName, i, entry)
+}
+
+func newIndexEntry(fileNode *FileNode) indexEntry {
+	return indexEntry{
+		folded:   strings.ToLower(fileNode.file.Name),
+		fileNode: fileNode,
+	}
+}
+
+func compareEntries(a indexEntry, b indexEntry) int {
+	if c := strings.Compare(a.folded, b.folded); c != 0 {
+		return c
+	}
+	return strings.Compare(a.fileNode.file.Paths, b.fileNode.file.Paths)
+}
+
+// Lookup returns the FileNode with the given path, or
+// nil if there is none
+func (index *FolderIndex) Lookup(path string) *FileNode {
+	return index.byPath[path]
+}
+
+// LookupID returns the FileNode whose Folder has 'id',
+// or nil if there is none
+func (index *FolderIndex) LookupID(id uuid.UUID) *FileNode {
+	return index.byID[id]
+}
+
+func (index *FolderIndex) addID(fileNode *FileNode) {
+	if id := fileNode.file.ID; id != uuid.Nil {
+		if _, exists := index.byID[id]; !exists {
+			index.byID[id] = fileNode
+		}
+	}
+}
+
+func (index *FolderIndex) addPath(fileNode *FileNode) {
+	if _, exists := index.byPath[fileNode.file.Paths]; !exists {
+		index.byPath[fileNode.file.Paths] = fileNode
+	}
+}
+
+// RemoveSubtree removes the paths of 'fileNode' and every
+// FileNode below it, before their paths are changed
+func (index *FolderIndex) RemoveSubtree(fileNode *FileNode) {
+	if index.byPath[fileNode.file.Paths] == fileNode {
+		delete(index.byPath, fileNode.file.Paths)
+	}
+	for _, childNode := range fileNode.children {
+		index.RemoveSubtree(childNode)
+	}
+}
+
+// AddSubtree adds the paths of 'fileNode' and every
+// FileNode below it, after their paths are changed
+func (index *FolderIndex) AddSubtree(fileNode *FileNode) {
+	index.addPath(fileNode)
+	for _, childNode := range fileNode.children {
+		index.AddSubtree(childNode)
+	}
+}
+
+// withPrefix returns the entries whose lower case
+// name starts with 'prefix', which must be lower case
+func (index *FolderIndex) withPrefix(prefix string) []indexEntry {
+	start, _ := slices.BinarySearchFunc(index.byName, prefix, func(e indexEntry, target string) int {
+		return strings.Compare(e.folded, target)
+	})
+
+	end := start
+	for end < len(index.byName) && strings.HasPrefix(index.byName[end].folded, prefix) {
+		end++
+	}
+
+	return index.byName[start:end]
+}
@@ -0,0 +1,112 @@
+package folder
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// FolderStats describes the shape of one or more subtrees,
+// measured from the folders they start at
+type FolderStats struct {
+	// Folders is the number of folders counted, including
+	// the folders the subtrees start at
+	Folders int `json:"folders"`
+	// Descendants is the number of folders below the
+	// folders the subtrees start at
+	Descendants int `json:"descendants"`
+	// Leaves is the number of folders with no children
+	Leaves int `json:"leaves"`
+	// MaxDepth is the number of levels below the folders
+	// the subtrees start at, 0 if none have children
+	MaxDepth int `json:"max_depth"`
+	// Breadth is the number of folders on each level, where
+	// level 0 holds the folders the subtrees start at
+	Breadth []int `json:"breadth"`
+}
+
+// CollectStats returns the FolderStats of the subtrees
+// starting at each FileNode in 'start', walking them one
+// level at a time using their children
+func CollectStats(start []*FileNode) FolderStats {
+	stats := FolderStats{
+		Breadth: []int{},
+	}
+
+	level := start
+	for len(level) > 0 {
+		stats.Breadth = append(stats.Breadth, len(level))
+		stats.Folders += len(level)
+
+		next := []*FileNode{}
+		for _, fileNode := range level {
+			if len(fileNode.children) == 0 {
+				stats.Leaves++
+			}
+			next = append(next, fileNode.children...)
+		}
+		level = next
+	}
+
+	stats.Descendants = stats.Folders - len(start)
+	if len(stats.Breadth) > 0 {
+		stats.MaxDepth = len(stats.Breadth) - 1
+	}
+
+	return stats
+}
+
+// CountDescendants sets the descendant count of 'fileNode'
+// and every FileNode below it, and returns its count
+func CountDescendants(fileNode *FileNode) int {
+	count := 0
+	for _, childNode := range fileNode.children {
+		count += CountDescendants(childNode) + 1
+	}
+	fileNode.descendants = count
+
+	return count
+}
+
+// AddDescendants adds 'delta' to the descendant count of
+// 'fileNode' and every FileNode above it
+func AddDescendants(fileNode *FileNode, delta int) {
+	for ; fileNode != nil; fileNode = fileNode.parent {
+		fileNode.descendants += delta
+	}
+}
+
+// Stats returns the FolderStats of the subtree starting
+// at the folder with 'path' in the organization
+func (f *driver) Stats(orgID uuid.UUID, path string) (FolderStats, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return FolderStats{}, err
+	}
+
+	return CollectStats([]*FileNode{fileNode}), nil
+}
+
+// OrgStats returns the FolderStats of every tree in the
+// organization, starting at their root folders
+func (f *driver) OrgStats(orgID uuid.UUID) (FolderStats, error) {
+	org, err := f.findOrg(orgID)
+	if err != nil {
+		return FolderStats{}, err
+	}
+
+	return CollectStats(FindRootNodes(org.folders)), nil
+}
+
+// DescendantCount returns the number of folders below the
+// folder with 'path', which is looked up rather than counted
+// when the driver keeps incremental stats
+func (f *driver) DescendantCount(orgID uuid.UUID, path string) (int, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return 0, err
+	}
+
+	if f.incrementalStats {
+		return fileNode.descendants, nil
+	}
+	return CollectStats([]*FileNode{fileNode}).Descendants, nil
+}
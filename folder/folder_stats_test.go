@@ -0,0 +1,204 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// exampleFolders is the example tree from the README
+var exampleFolders = []folder.Folder{
+	{
+		Name:  "alpha",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha",
+	},
+	{
+		Name:  "bravo",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha.bravo",
+	},
+	{
+		Name:  "charlie",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha.bravo.charlie",
+	},
+	{
+		Name:  "delta",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha.delta",
+	},
+	{
+		Name:  "echo",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha.delta.echo",
+	},
+	{
+		Name:  "foxtrot",
+		OrgId: uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7"),
+		Paths: "foxtrot",
+	},
+	{
+		Name:  "golf",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "golf",
+	},
+}
+
+func Test_folder_Stats(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		want  folder.FolderStats
+		err   error
+	}{
+		{
+			name:  "Root with subtrees",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			want: folder.FolderStats{
+				Folders:     5,
+				Descendants: 4,
+				Leaves:      2,
+				MaxDepth:    2,
+				Breadth:     []int{1, 2, 2},
+			},
+		},
+		{
+			name:  "Leaf folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.bravo.charlie",
+			want: folder.FolderStats{
+				Folders:     1,
+				Descendants: 0,
+				Leaves:      1,
+				MaxDepth:    0,
+				Breadth:     []int{1},
+			},
+		},
+		{
+			name:  "Folder name instead of path",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "charlie",
+			want: folder.FolderStats{
+				Folders:     1,
+				Descendants: 0,
+				Leaves:      1,
+				MaxDepth:    0,
+				Breadth:     []int{1},
+			},
+		},
+		{
+			name:  "Invalid path",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.charlie",
+			err:   errors.New("error: folder does not exist"),
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			path:  "alpha",
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get, err := f.Stats(tt.orgID, tt.path)
+
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("Stats() = %v, want %v for error", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stats() = %v, want nil for error", err)
+			}
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("Stats() = %v, want %v", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_OrgStats(t *testing.T) {
+	t.Parallel()
+	f := folder.NewDriver(exampleFolders)
+
+	get, err := f.OrgStats(uuid.FromStringOrNil(folder.DefaultOrgID))
+	if err != nil {
+		t.Fatalf("OrgStats() = %v, want nil for error", err)
+	}
+	want := folder.FolderStats{
+		Folders:     6,
+		Descendants: 4,
+		Leaves:      3,
+		MaxDepth:    2,
+		Breadth:     []int{2, 2, 2},
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("OrgStats() = %v, want %v", get, want)
+	}
+
+	_, err = f.OrgStats(uuid.FromStringOrNil(folder.DefaultOrgID + "."))
+	if err == nil {
+		t.Errorf("OrgStats() = nil, want error for invalid orgID")
+	}
+}
+
+func Test_folder_DescendantCount(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	moves := [...]struct {
+		src  string
+		dst  string
+		want map[string]int
+	}{
+		{
+			src: "bravo",
+			dst: "delta",
+			want: map[string]int{
+				"alpha":             4,
+				"alpha.delta":       3,
+				"alpha.delta.bravo": 1,
+				"golf":              0,
+			},
+		},
+		{
+			src: "delta",
+			dst: "golf",
+			want: map[string]int{
+				"alpha":            0,
+				"golf":             4,
+				"golf.delta":       3,
+				"golf.delta.bravo": 1,
+			},
+		},
+	}
+
+	for _, opts := range [][]folder.DriverOption{nil, {folder.WithIncrementalStats()}} {
+		f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...), opts...)
+		for _, move := range moves {
+			if _, err := f.MoveFolder(move.src, move.dst); err != nil {
+				t.Fatalf("MoveFolder() = %v, want nil for error", err)
+			}
+
+			for path, want := range move.want {
+				get, err := f.DescendantCount(orgID, path)
+				if err != nil {
+					t.Fatalf("DescendantCount() = %v, want nil for error", err)
+				}
+				if get != want {
+					t.Errorf("DescendantCount(%s) = %d after moving %s to %s, want %d", path, get, move.src, move.dst, want)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package folder
+
+import (
+	"errors"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// DefaultFuzzyThreshold is the similarity a name needs to be
+// matched by SearchFuzzy, or to be suggested in a NotFoundError
+const DefaultFuzzyThreshold = 0.6
+
+// MaxSuggestions is the most names suggested in a NotFoundError
+const MaxSuggestions = 3
+
+// Similarity returns how alike two names are, from 0 for
+// nothing in common to 1 for equal names, based on the
+// number of single letter edits turning one into the other
+func Similarity(a string, b string) float64 {
+	runesA, runesB := []rune(a), []rune(b)
+	longest := max(len(runesA), len(runesB))
+	if longest == 0 {
+		return 1
+	}
+
+	return 1 - float64(EditDistance(runesA, runesB))/float64(longest)
+}
+
+// EditDistance returns the Levenshtein distance between
+// 'a' and 'b', keeping only two rows of the table
+func EditDistance(a []rune, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+// SuggestNames returns up to 'limit' distinct folder names
+// in 'folders' most similar to 'ref', ignoring case, which
+// are at least DefaultFuzzyThreshold alike. If 'ref' is a
+// path of more than one name, split using 'codec', folder
+// paths are suggested instead. 'ref' itself is never suggested.
+func SuggestNames(folders []*FileNode, ref string, limit int, codec PathCodec) []string {
+	type suggestion struct {
+		name  string
+		score float64
+	}
+	suggestions := []suggestion{}
+	seen := map[string]bool{}
+	isPath := len(codec.Split(ref)) > 1
+	seen[ref] = true
+	ref = strings.ToLower(ref)
+
+	for _, fileNode := range folders {
+		name := fileNode.file.Name
+		if isPath {
+			name = fileNode.file.Paths
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		score := Similarity(ref, strings.ToLower(name))
+		if score >= DefaultFuzzyThreshold {
+			suggestions = append(suggestions, suggestion{name: name, score: score})
+		}
+	}
+
+	slices.SortFunc(suggestions, func(a suggestion, b suggestion) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.name, b.name)
+	})
+
+	names := []string{}
+	for i := 0; i < len(suggestions) && i < limit; i++ {
+		names = append(names, suggestions[i].name)
+	}
+
+	return names
+}
+
+// addSuggestions fills in the names suggested by a
+// NotFoundError for a folder, taken from its organization.
+// None are suggested if it has no orgID, as names from other
+// organizations must not be given out.
+func (f *driver) addSuggestions(err error) error {
+	notFound := &NotFoundError{}
+	if !errors.As(err, &notFound) || notFound.Kind == KindOrganization || notFound.OrgID == uuid.Nil {
+		return err
+	}
+
+	notFound.Suggestions = SuggestNames(f.orgs[notFound.OrgID].folders, notFound.Ref, MaxSuggestions, f.codec)
+
+	return notFound
+}
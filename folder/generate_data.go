@@ -0,0 +1,488 @@
+package folder
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+
+	"github.com/gofrs/uuid"
+	"github.com/lucasepe/codename"
+)
+
+// Distribution is a uniform range of whole numbers, including
+// both Min and Max, that the generator samples from
+type Distribution struct {
+	Min int
+	Max int
+}
+
+// Sample returns a number picked uniformly from the range
+func (d Distribution) Sample(rng *rand.Rand) int {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + rng.Intn(d.Max-d.Min+1)
+}
+
+// NameUniqueness is the scope in which generated
+// folder names must not repeat
+type NameUniqueness int
+
+const (
+	// UniqueNone allows any folder names to repeat
+	UniqueNone NameUniqueness = iota
+	// UniquePerOrg stops names repeating within an organization
+	UniquePerOrg
+	// UniqueGlobal stops names repeating across all organizations
+	UniqueGlobal
+)
+
+// CollisionStrategy is how the generator replaces a name
+// that has already been used
+type CollisionStrategy int
+
+const (
+	// CollisionSuffix appends "-2", "-3", ... to the name
+	CollisionSuffix CollisionStrategy = iota
+	// CollisionRetry generates new codenames, and falls back
+	// to a suffix if none of them are free
+	CollisionRetry
+)
+
+// nameRetries is the number of codenames CollisionRetry
+// tries before falling back to a suffix
+const nameRetries = 10
+
+// GeneratorConfig describes the shape of the synthetic data
+// produced by GenerateDataWithConfig. The same config always
+// produces the same folders.
+type GeneratorConfig struct {
+	// Seed makes the generated data reproducible
+	Seed int64
+
+	// NumOrgs is the number of organizations to generate, and
+	// RootsPerOrg the number of root folders in each of them
+	NumOrgs     int
+	RootsPerOrg int
+
+	// IncludeDefaultOrg makes the first organization use
+	// DefaultOrgID instead of a generated UUID
+	IncludeDefaultOrg bool
+
+	// Depth is the number of levels in each root folder's tree,
+	// where the root folder itself is level 1
+	Depth Distribution
+
+	// FanOut is the number of children of each folder that is
+	// not on the last level of its tree
+	FanOut Distribution
+
+	// DuplicateNameRate is the chance, between 0 and 1, that a
+	// folder reuses the name of a folder above it in its tree
+	DuplicateNameRate float64
+
+	// Uniqueness stops names repeating within the given scope,
+	// replacing repeated names using OnCollision
+	Uniqueness  NameUniqueness
+	OnCollision CollisionStrategy
+
+	// Workers is the number of goroutines generating subtrees
+	// at once. 0 or 1 generates the trees one after another, and
+	// the output is the same for any number of workers.
+	Workers int
+}
+
+// DefaultGeneratorConfig returns a GeneratorConfig matching
+// the shape of the data produced by GenerateData
+func DefaultGeneratorConfig() GeneratorConfig {
+	return GeneratorConfig{
+		Seed:              1,
+		NumOrgs:           2,
+		RootsPerOrg:       2,
+		IncludeDefaultOrg: true,
+		Depth:             Distribution{Min: MaxDepth, Max: MaxDepth},
+		FanOut:            Distribution{Min: 1, Max: MaxChild},
+	}
+}
+
+// Validate returns an error if the config can not be
+// used to generate data
+func (c GeneratorConfig) Validate() error {
+	if c.Workers < 0 {
+		return errors.New("error: number of workers cannot be negative")
+	}
+	if c.NumOrgs < 0 || c.RootsPerOrg < 0 {
+		return errors.New("error: number of organizations and roots cannot be negative")
+	}
+	if c.Depth.Min < 1 || c.Depth.Max < c.Depth.Min {
+		return errors.New("error: depth must be a range of at least 1")
+	}
+	if c.FanOut.Min < 0 || c.FanOut.Max < c.FanOut.Min {
+		return errors.New("error: fan out must be a range of at least 0")
+	}
+	if c.DuplicateNameRate < 0 || c.DuplicateNameRate > 1 {
+		return errors.New("error: duplicate name rate must be between 0 and 1")
+	}
+	if c.Uniqueness < UniqueNone || c.Uniqueness > UniqueGlobal {
+		return errors.New("error: unknown name uniqueness")
+	}
+	if c.OnCollision < CollisionSuffix || c.OnCollision > CollisionRetry {
+		return errors.New("error: unknown collision strategy")
+	}
+
+	return nil
+}
+
+// genNode is a generated folder, before its path is known
+type genNode struct {
+	id   uuid.UUID
+	name string
+	// parent is the index of the parent genNode in the
+	// same tree, or -1 for the root folder
+	parent int
+}
+
+// genRoot is a root folder that still has to be generated
+type genRoot struct {
+	orgID uuid.UUID
+	seed  int64
+}
+
+// NameCollision is a generated folder whose name had
+// already been used within the configured scope
+type NameCollision struct {
+	OrgId uuid.UUID
+	// Name is the name that was generated, and Resolved the name
+	// the folder was given, which is the same with UniqueNone
+	Name     string
+	Resolved string
+	Paths    string
+}
+
+// CollisionReport lists every name collision found
+// while generating data
+type CollisionReport struct {
+	Collisions []NameCollision
+}
+
+// GenerateDataWithConfig returns a slice of Folders generated
+// according to 'cfg', with each tree listed in pre-order
+func GenerateDataWithConfig(cfg GeneratorConfig) ([]Folder, error) {
+	folders, _, err := GenerateDataWithReport(cfg)
+	return folders, err
+}
+
+// GenerateDataWithReport returns the same Folders as
+// GenerateDataWithConfig, along with the name collisions
+// found while generating them
+func GenerateDataWithReport(cfg GeneratorConfig) ([]Folder, CollisionReport, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, CollisionReport{}, err
+	}
+
+	roots := planRoots(cfg)
+	trees := generateTrees(roots, cfg)
+	collisions := resolveNames(roots, trees, cfg)
+
+	folders := []Folder{}
+	report := CollisionReport{Collisions: []NameCollision{}}
+	for i, root := range roots {
+		tree := buildFolders(root.orgID, trees[i])
+		for _, c := range collisions[i] {
+			c.Paths = tree[c.index].Paths
+			report.Collisions = append(report.Collisions, c.NameCollision)
+		}
+		folders = append(folders, tree...)
+	}
+
+	return folders, report, nil
+}
+
+// treeCollision is a NameCollision found at 'index' of
+// a generated tree, before its path is known
+type treeCollision struct {
+	NameCollision
+	index int
+}
+
+// resolveNames renames the nodes of 'trees' whose name is
+// already used within the configured scope, and returns the
+// collisions found in each tree. Trees are visited in order
+// after they have all been generated, so the result does not
+// depend on how many workers generated them.
+func resolveNames(roots []genRoot, trees [][]genNode, cfg GeneratorConfig) [][]treeCollision {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	used := map[uuid.UUID]map[string]bool{}
+	collisions := make([][]treeCollision, len(trees))
+
+	for i, root := range roots {
+		// Without uniqueness, collisions are still reported per
+		// organization as that is where lookups by name happen
+		scope := root.orgID
+		if cfg.Uniqueness == UniqueGlobal {
+			scope = uuid.Nil
+		}
+		if used[scope] == nil {
+			used[scope] = map[string]bool{}
+		}
+		taken := func(name string) bool {
+			return used[scope][name]
+		}
+
+		for j := range trees[i] {
+			node := &trees[i][j]
+			if !taken(node.name) {
+				used[scope][node.name] = true
+				continue
+			}
+
+			resolved := node.name
+			if cfg.Uniqueness != UniqueNone {
+				resolved = replaceName(rng, node.name, cfg.OnCollision, taken)
+				used[scope][resolved] = true
+			}
+			collisions[i] = append(collisions[i], treeCollision{
+				NameCollision: NameCollision{
+					OrgId:    root.orgID,
+					Name:     node.name,
+					Resolved: resolved,
+				},
+				index: j,
+			})
+			node.name = resolved
+		}
+	}
+
+	return collisions
+}
+
+// replaceName returns a name to use instead of 'name'
+// which is not 'taken', following 'strategy'
+func replaceName(rng *rand.Rand, name string, strategy CollisionStrategy, taken func(string) bool) string {
+	if strategy == CollisionRetry {
+		for i := 0; i < nameRetries; i++ {
+			candidate := codename.Generate(rng, 0)
+			if !taken(candidate) {
+				return candidate
+			}
+		}
+	}
+
+	return SuffixName(name, taken)
+}
+
+// SuffixName returns 'name' followed by the lowest
+// suffix "-2", "-3", ... which is not 'taken'
+func SuffixName(name string, taken func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// genSplitFactor is how many subtrees per worker trees are
+// split into, so a few large subtrees do not leave the other
+// workers idle
+const genSplitFactor = 4
+
+// generateTrees returns the generated nodes of every root
+// folder, in the same order as 'roots'. Each subtree only
+// depends on its own seed and the names above it, so trees
+// are split into subtrees which are handed out to a bounded
+// pool of workers without changing the result.
+func generateTrees(roots []genRoot, cfg GeneratorConfig) [][]genNode {
+	plans := make([]*genPlan, len(roots))
+	tasks := []*genPlan{}
+	for i, root := range roots {
+		plans[i] = &genPlan{seed: root.seed, level: 1}
+		tasks = append(tasks, plans[i])
+	}
+
+	if cfg.Workers > 1 {
+		// Expand the trees a level at a time until there
+		// are enough subtrees to share out
+		for len(tasks) > 0 && len(tasks) < cfg.Workers*genSplitFactor {
+			next := []*genPlan{}
+			for _, task := range tasks {
+				task.expand(cfg)
+				next = append(next, task.children...)
+			}
+			tasks = next
+		}
+	}
+
+	workers := max(min(cfg.Workers, len(tasks)), 1)
+	jobs := make(chan *genPlan)
+	wg := sync.WaitGroup{}
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range jobs {
+				task.generate(cfg)
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		jobs <- task
+	}
+	close(jobs)
+	wg.Wait()
+
+	trees := make([][]genNode, len(roots))
+	for i, plan := range plans {
+		trees[i] = plan.flatten()
+	}
+
+	return trees
+}
+
+// planRoots returns the organization and seed of every root
+// folder, so each tree can be generated independently
+func planRoots(cfg GeneratorConfig) []genRoot {
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	roots := []genRoot{}
+
+	for i := 0; i < cfg.NumOrgs; i++ {
+		orgID := newSeededUUID(rng)
+		if i == 0 && cfg.IncludeDefaultOrg {
+			orgID = uuid.FromStringOrNil(DefaultOrgID)
+		}
+		for j := 0; j < cfg.RootsPerOrg; j++ {
+			roots = append(roots, genRoot{
+				orgID: orgID,
+				seed:  rng.Int63(),
+			})
+		}
+	}
+
+	return roots
+}
+
+// newSeededUUID returns a version 4 UUID read from 'rng'
+// rather than from a cryptographic source
+func newSeededUUID(rng *rand.Rand) uuid.UUID {
+	u := uuid.UUID{}
+	rng.Read(u[:])
+	u.SetVersion(uuid.V4)
+	u.SetVariant(uuid.VariantRFC4122)
+
+	return u
+}
+
+// genPlan is a subtree of a generated tree, which only
+// depends on its seed, its level and the names above it
+type genPlan struct {
+	seed  int64
+	level int
+	// levels is the number of levels in the tree, drawn
+	// by its root folder
+	levels    int
+	ancestors []string
+
+	// expanded is whether the folder and the plans of
+	// its children have been generated
+	expanded bool
+	node     genNode
+	children []*genPlan
+}
+
+// genIDSalt is mixed into the seed of a plan to seed the IDs
+// of its folders, so drawing IDs does not change the names
+// and shapes of the trees generated for a seed
+const genIDSalt = 0x5eed1d5
+
+// expand generates the folder at the top of the plan, and
+// the plans of its children, using only the seed of the plan
+func (p *genPlan) expand(cfg GeneratorConfig) {
+	if p.expanded {
+		return
+	}
+	p.expanded = true
+
+	rng := rand.New(rand.NewSource(p.seed))
+	if p.level == 1 {
+		p.levels = cfg.Depth.Sample(rng)
+	}
+	p.node = genNode{
+		id:     newSeededUUID(rand.New(rand.NewSource(p.seed ^ genIDSalt))),
+		name:   generateName(rng, cfg, p.ancestors),
+		parent: -1,
+	}
+	if p.level >= p.levels {
+		return
+	}
+
+	ancestors := append(p.ancestors[:len(p.ancestors):len(p.ancestors)], p.node.name)
+	numOfChild := cfg.FanOut.Sample(rng)
+	for i := 0; i < numOfChild; i++ {
+		p.children = append(p.children, &genPlan{
+			seed:      rng.Int63(),
+			level:     p.level + 1,
+			levels:    p.levels,
+			ancestors: ancestors,
+		})
+	}
+}
+
+// generate expands the whole subtree of the plan
+func (p *genPlan) generate(cfg GeneratorConfig) {
+	p.expand(cfg)
+	for _, child := range p.children {
+		child.generate(cfg)
+	}
+}
+
+// flatten returns the generated folders of the plan in
+// pre-order, with parents indexed from its top folder
+func (p *genPlan) flatten() []genNode {
+	nodes := []genNode{p.node}
+	for _, child := range p.children {
+		offset := len(nodes)
+		for _, node := range child.flatten() {
+			if node.parent < 0 {
+				node.parent = 0
+			} else {
+				node.parent += offset
+			}
+			nodes = append(nodes, node)
+		}
+	}
+
+	return nodes
+}
+
+// generateName returns a new codename, or the name of one
+// of the 'ancestors' of the folder at the configured
+// duplicate rate
+func generateName(rng *rand.Rand, cfg GeneratorConfig, ancestors []string) string {
+	if cfg.DuplicateNameRate > 0 && len(ancestors) > 0 && rng.Float64() < cfg.DuplicateNameRate {
+		return ancestors[rng.Intn(len(ancestors))]
+	}
+
+	return codename.Generate(rng, 0)
+}
+
+// buildFolders returns the Folders for a generated tree,
+// building each path from the path of its parent
+func buildFolders(orgID uuid.UUID, nodes []genNode) []Folder {
+	folders := make([]Folder, 0, len(nodes))
+	for _, node := range nodes {
+		paths := AppendPath("", node.name)
+		if node.parent >= 0 {
+			paths = AppendPath(folders[node.parent].Paths, node.name)
+		}
+		folders = append(folders, Folder{
+			ID:    node.id,
+			Name:  node.name,
+			OrgId: orgID,
+			Paths: paths,
+		})
+	}
+
+	return folders
+}
@@ -0,0 +1,363 @@
+package folder_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_GenerateDataWithConfig(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name string
+		cfg  folder.GeneratorConfig
+		err  error
+	}{
+		{
+			name: "Default config",
+			cfg:  folder.DefaultGeneratorConfig(),
+		},
+		{
+			name: "Many small trees",
+			cfg: folder.GeneratorConfig{
+				Seed:        42,
+				NumOrgs:     5,
+				RootsPerOrg: 3,
+				Depth:       folder.Distribution{Min: 1, Max: 3},
+				FanOut:      folder.Distribution{Min: 0, Max: 2},
+			},
+		},
+		{
+			name: "Frequent duplicate names",
+			cfg: folder.GeneratorConfig{
+				Seed:              7,
+				NumOrgs:           1,
+				RootsPerOrg:       1,
+				Depth:             folder.Distribution{Min: 4, Max: 4},
+				FanOut:            folder.Distribution{Min: 3, Max: 3},
+				DuplicateNameRate: 0.5,
+			},
+		},
+		{
+			name: "Invalid depth",
+			cfg: folder.GeneratorConfig{
+				NumOrgs:     1,
+				RootsPerOrg: 1,
+				Depth:       folder.Distribution{Min: 0, Max: 2},
+				FanOut:      folder.Distribution{Min: 1, Max: 1},
+			},
+			err: errors.New("error: depth must be a range of at least 1"),
+		},
+		{
+			name: "Invalid fan out",
+			cfg: folder.GeneratorConfig{
+				NumOrgs:     1,
+				RootsPerOrg: 1,
+				Depth:       folder.Distribution{Min: 1, Max: 2},
+				FanOut:      folder.Distribution{Min: 3, Max: 2},
+			},
+			err: errors.New("error: fan out must be a range of at least 0"),
+		},
+		{
+			name: "Invalid duplicate name rate",
+			cfg: folder.GeneratorConfig{
+				NumOrgs:           1,
+				RootsPerOrg:       1,
+				Depth:             folder.Distribution{Min: 1, Max: 2},
+				FanOut:            folder.Distribution{Min: 1, Max: 2},
+				DuplicateNameRate: 1.5,
+			},
+			err: errors.New("error: duplicate name rate must be between 0 and 1"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get, err := folder.GenerateDataWithConfig(tt.cfg)
+
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("GenerateDataWithConfig() = %v, want %v for error", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GenerateDataWithConfig() = %v, want nil for error", err)
+			}
+
+			again, _ := folder.GenerateDataWithConfig(tt.cfg)
+			if !reflect.DeepEqual(get, again) {
+				t.Errorf("GenerateDataWithConfig() is not reproducible for seed %d", tt.cfg.Seed)
+			}
+
+			orgs := map[uuid.UUID]bool{}
+			roots := 0
+			for _, f := range get {
+				orgs[f.OrgId] = true
+				levels := strings.Count(f.Paths, ".") + 1
+				if levels == 1 {
+					roots++
+				}
+				if levels > tt.cfg.Depth.Max {
+					t.Errorf("GenerateDataWithConfig() made %s with %d levels, want at most %d", f.Paths, levels, tt.cfg.Depth.Max)
+				}
+				if !strings.HasSuffix(f.Paths, f.Name) {
+					t.Errorf("GenerateDataWithConfig() made %s with name %s", f.Paths, f.Name)
+				}
+			}
+
+			if len(orgs) != tt.cfg.NumOrgs {
+				t.Errorf("GenerateDataWithConfig() made %d orgs, want %d", len(orgs), tt.cfg.NumOrgs)
+			}
+			if roots != tt.cfg.NumOrgs*tt.cfg.RootsPerOrg {
+				t.Errorf("GenerateDataWithConfig() made %d roots, want %d", roots, tt.cfg.NumOrgs*tt.cfg.RootsPerOrg)
+			}
+			if tt.cfg.IncludeDefaultOrg && !orgs[uuid.FromStringOrNil(folder.DefaultOrgID)] {
+				t.Errorf("GenerateDataWithConfig() did not use the default orgID")
+			}
+		})
+	}
+}
+
+func Test_folder_GenerateData_IDs(t *testing.T) {
+	t.Parallel()
+	generated, _ := folder.GenerateDataWithConfig(folder.DefaultGeneratorConfig())
+
+	for name, folders := range map[string][]folder.Folder{
+		"GenerateData":           folder.GenerateData(),
+		"GenerateDataWithConfig": generated,
+	} {
+		ids := map[uuid.UUID]bool{}
+		for _, f := range folders {
+			if f.ID == uuid.Nil {
+				t.Errorf("%s() made %s without an ID", name, f.Paths)
+			}
+			if ids[f.ID] {
+				t.Errorf("%s() gave ID %s to more than one folder", name, f.ID)
+			}
+			ids[f.ID] = true
+		}
+	}
+}
+
+// Test_folder_GenerateDataWithConfig_Names pins the paths made
+// for a seed, which must not change when IDs are drawn
+func Test_folder_GenerateDataWithConfig_Names(t *testing.T) {
+	t.Parallel()
+	cfg := folder.GeneratorConfig{
+		Seed:        5,
+		NumOrgs:     1,
+		RootsPerOrg: 2,
+		Depth:       folder.Distribution{Min: 2, Max: 3},
+		FanOut:      folder.Distribution{Min: 1, Max: 2},
+	}
+	want := []string{
+		"subtle-caliban",
+		"subtle-caliban.living-master-chief",
+		"subtle-caliban.living-master-chief.exciting-scarlet-witch",
+		"charming-dark-phoenix",
+		"charming-dark-phoenix.current-hairball",
+		"charming-dark-phoenix.closing-raphael",
+	}
+
+	folders, err := folder.GenerateDataWithConfig(cfg)
+	if err != nil {
+		t.Fatalf("GenerateDataWithConfig() = %v, want nil for error", err)
+	}
+	get := []string{}
+	for _, f := range folders {
+		get = append(get, f.Paths)
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GenerateDataWithConfig() = %q, want %q", get, want)
+	}
+}
+
+func Test_folder_GenerateDataWithConfig_Seed(t *testing.T) {
+	t.Parallel()
+	cfg := folder.DefaultGeneratorConfig()
+	first, _ := folder.GenerateDataWithConfig(cfg)
+
+	cfg.Seed++
+	second, _ := folder.GenerateDataWithConfig(cfg)
+
+	if reflect.DeepEqual(first, second) {
+		t.Errorf("GenerateDataWithConfig() made the same data for different seeds")
+	}
+}
+
+func Test_folder_GenerateDataWithConfig_Workers(t *testing.T) {
+	t.Parallel()
+	for _, cfg := range []folder.GeneratorConfig{
+		{
+			Seed:              99,
+			NumOrgs:           3,
+			RootsPerOrg:       4,
+			Depth:             folder.Distribution{Min: 2, Max: 4},
+			FanOut:            folder.Distribution{Min: 1, Max: 3},
+			DuplicateNameRate: 0.1,
+		},
+		// A single tree is shared out by its subtrees
+		{
+			Seed:              99,
+			NumOrgs:           1,
+			RootsPerOrg:       1,
+			Depth:             folder.Distribution{Min: 5, Max: 5},
+			FanOut:            folder.Distribution{Min: 2, Max: 4},
+			DuplicateNameRate: 0.1,
+		},
+	} {
+		want, _ := folder.GenerateDataWithConfig(cfg)
+
+		for _, workers := range []int{2, 3, 8, 64} {
+			cfg.Workers = workers
+			get, err := folder.GenerateDataWithConfig(cfg)
+			if err != nil {
+				t.Fatalf("GenerateDataWithConfig() = %v, want nil for error", err)
+			}
+			if !reflect.DeepEqual(get, want) {
+				t.Errorf("GenerateDataWithConfig() with %d workers and %d roots differs from 1 worker", workers, cfg.RootsPerOrg)
+			}
+		}
+	}
+}
+
+// benchmarkConfig generates trees of the same shape as
+// GenerateData, with 'roots' root folders in total
+func benchmarkConfig(roots int, workers int) folder.GeneratorConfig {
+	cfg := folder.DefaultGeneratorConfig()
+	cfg.NumOrgs = 1
+	cfg.RootsPerOrg = roots
+	cfg.Workers = workers
+	return cfg
+}
+
+func Benchmark_folder_GenerateData(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		folder.GenerateData()
+	}
+}
+
+func Benchmark_folder_GenerateDataWithConfig(b *testing.B) {
+	for _, roots := range []int{folder.MaxRootSet, 64} {
+		for _, workers := range []int{1, 2, 4, 8} {
+			b.Run(fmt.Sprintf("roots=%d/workers=%d", roots, workers), func(b *testing.B) {
+				cfg := benchmarkConfig(roots, workers)
+				for i := 0; i < b.N; i++ {
+					if _, err := folder.GenerateDataWithConfig(cfg); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func Test_folder_GenerateDataWithReport(t *testing.T) {
+	t.Parallel()
+	base := folder.GeneratorConfig{
+		Seed:              3,
+		NumOrgs:           2,
+		RootsPerOrg:       2,
+		Depth:             folder.Distribution{Min: 3, Max: 3},
+		FanOut:            folder.Distribution{Min: 2, Max: 3},
+		DuplicateNameRate: 0.3,
+		Workers:           2,
+	}
+	tests := [...]struct {
+		name       string
+		uniqueness folder.NameUniqueness
+		strategy   folder.CollisionStrategy
+	}{
+		{
+			name:       "Duplicates are reported but kept",
+			uniqueness: folder.UniqueNone,
+		},
+		{
+			name:       "Unique per org with suffixes",
+			uniqueness: folder.UniquePerOrg,
+			strategy:   folder.CollisionSuffix,
+		},
+		{
+			name:       "Unique per org with retries",
+			uniqueness: folder.UniquePerOrg,
+			strategy:   folder.CollisionRetry,
+		},
+		{
+			name:       "Unique globally with suffixes",
+			uniqueness: folder.UniqueGlobal,
+			strategy:   folder.CollisionSuffix,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := base
+			cfg.Uniqueness = tt.uniqueness
+			cfg.OnCollision = tt.strategy
+
+			get, report, err := folder.GenerateDataWithReport(cfg)
+			if err != nil {
+				t.Fatalf("GenerateDataWithReport() = %v, want nil for error", err)
+			}
+			if len(report.Collisions) == 0 {
+				t.Fatalf("GenerateDataWithReport() found no collisions, want some")
+			}
+
+			again, _, _ := folder.GenerateDataWithReport(cfg)
+			if !reflect.DeepEqual(get, again) {
+				t.Errorf("GenerateDataWithReport() is not reproducible for seed %d", cfg.Seed)
+			}
+
+			seen := map[string]bool{}
+			duplicates := 0
+			for _, f := range get {
+				key := f.OrgId.String() + "/" + f.Name
+				if tt.uniqueness == folder.UniqueGlobal {
+					key = f.Name
+				}
+				if seen[key] {
+					duplicates++
+				}
+				seen[key] = true
+			}
+
+			for _, c := range report.Collisions {
+				if !strings.HasSuffix(c.Paths, c.Resolved) {
+					t.Errorf("GenerateDataWithReport() reported %s for folder at %s", c.Resolved, c.Paths)
+				}
+				if tt.uniqueness == folder.UniqueNone && c.Resolved != c.Name {
+					t.Errorf("GenerateDataWithReport() renamed %s to %s, want it kept", c.Name, c.Resolved)
+				}
+			}
+
+			if tt.uniqueness == folder.UniqueNone && duplicates != len(report.Collisions) {
+				t.Errorf("GenerateDataWithReport() reported %d collisions, found %d", len(report.Collisions), duplicates)
+			}
+			if tt.uniqueness != folder.UniqueNone && duplicates != 0 {
+				t.Errorf("GenerateDataWithReport() left %d duplicate names", duplicates)
+			}
+		})
+	}
+}
+
+func Test_folder_SuffixName(t *testing.T) {
+	t.Parallel()
+	taken := map[string]bool{
+		"alpha":   true,
+		"alpha-2": true,
+		"alpha-3": true,
+	}
+
+	get := folder.SuffixName("alpha", func(name string) bool {
+		return taken[name]
+	})
+	if get != "alpha-4" {
+		t.Errorf("SuffixName() = %v, want %v", get, "alpha-4")
+	}
+}
@@ -1,6 +1,13 @@
 package folder
 
 import (
+	"encoding/base64"
+	"errors"
+	"iter"
+	"slices"
+	"strings"
+	"time"
+
 	"github.com/gofrs/uuid"
 )
 
@@ -20,17 +27,70 @@ func (f *driver) GetFoldersByOrgID(orgID uuid.UUID) []Folder {
 
 	res := []Folder{}
 	for _, f := range value.folders {
-		res = append(res, f.file)
+		res = append(res, f.file.clone())
 	}
 	return res
 }
 
+// FolderFilter selects folders by their attributes. The
+// zero value of each field matches every folder.
+type FolderFilter struct {
+	// Metadata holds the values a folder's metadata must
+	// have for each of its keys
+	Metadata map[string]string
+	// CreatedAfter, CreatedBefore, UpdatedAfter and UpdatedBefore
+	// bound the timestamps of a folder, excluding the bound itself
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	UpdatedAfter  time.Time
+	UpdatedBefore time.Time
+}
+
+// Match returns whether 'folder' has every attribute
+// the filter asks for
+func (filter FolderFilter) Match(folder Folder) bool {
+	for key, value := range filter.Metadata {
+		if got, exists := folder.Metadata[key]; !exists || got != value {
+			return false
+		}
+	}
+
+	return inRange(folder.CreatedAt, filter.CreatedAfter, filter.CreatedBefore) &&
+		inRange(folder.UpdatedAt, filter.UpdatedAfter, filter.UpdatedBefore)
+}
+
+// inRange returns whether 't' is after 'after' and before
+// 'before', where a zero bound is no bound
+func inRange(t time.Time, after time.Time, before time.Time) bool {
+	if !after.IsZero() && !t.After(after) {
+		return false
+	}
+	if !before.IsZero() && !t.Before(before) {
+		return false
+	}
+
+	return true
+}
+
+// GetFoldersByOrgIDWithFilter returns the Folders which
+// have a certain orgID and match 'filter'
+func (f *driver) GetFoldersByOrgIDWithFilter(orgID uuid.UUID, filter FolderFilter) []Folder {
+	res := []Folder{}
+	for _, folder := range f.GetFoldersByOrgID(orgID) {
+		if filter.Match(folder) {
+			res = append(res, folder)
+		}
+	}
+
+	return res
+}
+
 // GetChildren returns a slice of Folders containing
 // all the children of a FileNode 'parent'
 func GetChildren(parent *FileNode) []Folder {
 	nodeChildren := []Folder{}
 	for _, fileNodePtr := range parent.children {
-		nodeChildren = append(nodeChildren, fileNodePtr.file)
+		nodeChildren = append(nodeChildren, fileNodePtr.file.clone())
 		nodeChildren = append(nodeChildren, GetChildren(fileNodePtr)...)
 	}
 
@@ -38,8 +98,8 @@ func GetChildren(parent *FileNode) []Folder {
 }
 
 // GetAllChildFolders returns the slice of Folders generated using
-// GetChildren, but ensures that the orgID is valid, and the name of
-// the file exists in the organization
+// GetChildren, but ensures that the orgID is valid, and the name or
+// ID of the file exists in the organization
 func (f *driver) GetAllChildFolders(orgID uuid.UUID, name string) []Folder {
 	org, exists := f.orgs[orgID]
 
@@ -47,10 +107,16 @@ func (f *driver) GetAllChildFolders(orgID uuid.UUID, name string) []Folder {
 		return []Folder{}
 	}
 
+	name = f.normalizeRef(name)
 	var parentNode *FileNode = nil
-	for _, fileNode := range org.folders {
-		if fileNode.file.Name == name {
-			parentNode = fileNode
+	if id, err := uuid.FromString(name); err == nil {
+		parentNode = lookupID(org, id)
+	}
+	if parentNode == nil {
+		for _, fileNode := range org.folders {
+			if fileNode.file.Name == name {
+				parentNode = fileNode
+			}
 		}
 	}
 
@@ -60,3 +126,169 @@ func (f *driver) GetAllChildFolders(orgID uuid.UUID, name string) []Folder {
 
 	return GetChildren(parentNode)
 }
+
+// ChildListOptions limits the child folders returned
+// by GetAllChildFoldersWithOptions
+type ChildListOptions struct {
+	// MaxDepth is the number of levels below the folder to
+	// return, where 1 is its immediate children and 0 is
+	// no limit
+	MaxDepth int
+	// PageSize is the most folders to return at once, where
+	// 0 returns every folder in a single page
+	PageSize int
+	// Cursor is the NextCursor of the previous page, or
+	// empty for the first page
+	Cursor string
+}
+
+// ChildPage is a page of child folders, ordered by their
+// path with each name in it compared in turn
+type ChildPage struct {
+	Folders []Folder
+	// NextCursor continues the listing after this page, and
+	// is empty if there are no folders left
+	NextCursor string
+}
+
+// EncodeCursor returns an opaque cursor which continues
+// a listing after the folder with 'path'
+func EncodeCursor(path string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(path))
+}
+
+// DecodeCursor returns the path a cursor made by
+// EncodeCursor continues after
+func DecodeCursor(cursor string) (string, error) {
+	path, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.New("error: invalid cursor")
+	}
+
+	return string(path), nil
+}
+
+// ComparePaths compares two paths one name at a time,
+// so a folder sorts before its children and its children
+// sort before its next sibling
+func ComparePaths(a []string, b []string) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if c := strings.Compare(a[i], b[i]); c != 0 {
+			return c
+		}
+	}
+
+	return len(a) - len(b)
+}
+
+// sortedChildren returns the children of 'parentNode'
+// sorted by name, without reordering the FileNode itself
+func sortedChildren(parentNode *FileNode) []*FileNode {
+	children := slices.Clone(parentNode.children)
+	slices.SortStableFunc(children, func(a *FileNode, b *FileNode) int {
+		return strings.Compare(a.file.Name, b.file.Name)
+	})
+
+	return children
+}
+
+// GetAllChildFoldersWithOptions returns a page of the child
+// folders of the folder with 'name' in path order, starting
+// after 'opts.Cursor'. Subtrees that sort entirely before the
+// cursor are skipped without being walked.
+func (f *driver) GetAllChildFoldersWithOptions(orgID uuid.UUID, name string, opts ChildListOptions) (ChildPage, error) {
+	parentNode, err := f.findNode(orgID, name)
+	if err != nil {
+		return ChildPage{Folders: []Folder{}}, f.addSuggestions(err)
+	}
+
+	return f.childPage(parentNode, opts, func(*FileNode) bool { return true })
+}
+
+// childPage returns a page of the child folders of
+// 'parentNode' for which 'include' is true. Folders left out
+// are still walked, so their children can be included.
+func (f *driver) childPage(parentNode *FileNode, opts ChildListOptions, include func(*FileNode) bool) (ChildPage, error) {
+	var after []string
+	if opts.Cursor != "" {
+		cursorPath, err := DecodeCursor(opts.Cursor)
+		if err != nil {
+			return ChildPage{Folders: []Folder{}}, err
+		}
+		after = f.codec.Split(cursorPath)
+	}
+
+	// One extra folder is collected to tell whether
+	// there is another page after this one
+	limit := -1
+	if opts.PageSize > 0 {
+		limit = opts.PageSize + 1
+	}
+	folders := []Folder{}
+
+	var walk func(fileNode *FileNode, depth int)
+	walk = func(fileNode *FileNode, depth int) {
+		for _, childNode := range sortedChildren(fileNode) {
+			if len(folders) == limit {
+				return
+			}
+
+			if after != nil {
+				path := f.codec.Split(childNode.file.Paths)
+				c := ComparePaths(path, after)
+				if c < 0 && !isPathPrefix(path, after) {
+					continue
+				}
+				if c > 0 && include(childNode) {
+					folders = append(folders, childNode.file.clone())
+				}
+			} else if include(childNode) {
+				folders = append(folders, childNode.file.clone())
+			}
+
+			if opts.MaxDepth == 0 || depth < opts.MaxDepth {
+				walk(childNode, depth+1)
+			}
+		}
+	}
+	walk(parentNode, 1)
+
+	page := ChildPage{Folders: folders}
+	if len(folders) == limit {
+		page.Folders = folders[:opts.PageSize]
+		page.NextCursor = EncodeCursor(page.Folders[opts.PageSize-1].Paths)
+	}
+
+	return page, nil
+}
+
+// isPathPrefix returns whether 'prefix' is the path of
+// 'path' or of one of its ancestors
+func isPathPrefix(prefix []string, path []string) bool {
+	return len(prefix) <= len(path) && slices.Equal(prefix, path[:len(prefix)])
+}
+
+// ChildFolders returns an iterator over the same folders as
+// GetAllChildFolders, up to 'maxDepth' levels below the folder
+// with 'name' where 0 is no limit. Folders are visited lazily,
+// so the tree must not be changed while iterating.
+func (f *driver) ChildFolders(orgID uuid.UUID, name string, maxDepth int) (iter.Seq[Folder], error) {
+	parentNode, err := f.findNode(orgID, name)
+	if err != nil {
+		return func(yield func(Folder) bool) {}, f.addSuggestions(err)
+	}
+
+	return func(yield func(Folder) bool) {
+		for visit := range WalkNodes([]*FileNode{parentNode}, PreOrder) {
+			if visit.Depth == 0 {
+				continue
+			}
+			if !yield(visit.Folder) {
+				return
+			}
+			if maxDepth > 0 && visit.Depth >= maxDepth {
+				visit.SkipChildren()
+			}
+		}
+	}, nil
+}
@@ -1,8 +1,11 @@
 package folder_test
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/georgechieng-sc/interns-2022/folder"
 	"github.com/gofrs/uuid"
@@ -262,3 +265,358 @@ func Test_folder_GetAllChildFolders(t *testing.T) {
 		})
 	}
 }
+
+func Test_folder_GetAllChildFoldersWithOptions(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		src   string
+		orgID uuid.UUID
+		opts  folder.ChildListOptions
+		want  [][]string
+		err   error
+	}{
+		{
+			name:  "Single page in path order",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			want: [][]string{
+				{"alpha.bravo", "alpha.bravo.charlie", "alpha.delta", "alpha.delta.echo"},
+			},
+		},
+		{
+			name:  "Pages of two",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			opts:  folder.ChildListOptions{PageSize: 2},
+			want: [][]string{
+				{"alpha.bravo", "alpha.bravo.charlie"},
+				{"alpha.delta", "alpha.delta.echo"},
+			},
+		},
+		{
+			name:  "Pages of three",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			opts:  folder.ChildListOptions{PageSize: 3},
+			want: [][]string{
+				{"alpha.bravo", "alpha.bravo.charlie", "alpha.delta"},
+				{"alpha.delta.echo"},
+			},
+		},
+		{
+			name:  "Immediate children only",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			opts:  folder.ChildListOptions{MaxDepth: 1, PageSize: 1},
+			want: [][]string{
+				{"alpha.bravo"},
+				{"alpha.delta"},
+			},
+		},
+		{
+			name:  "Starting from a cursor",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			opts:  folder.ChildListOptions{Cursor: folder.EncodeCursor("alpha.bravo.charlie")},
+			want: [][]string{
+				{"alpha.delta", "alpha.delta.echo"},
+			},
+		},
+		{
+			name:  "Invalid cursor",
+			src:   "alpha",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			opts:  folder.ChildListOptions{Cursor: "not a cursor!"},
+			want:  [][]string{{}},
+			err:   errors.New("error: invalid cursor"),
+		},
+		{
+			name:  "Test with Non Existent FileName",
+			src:   "hotel",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			want:  [][]string{{}},
+			err:   errors.New("error: folder does not exist"),
+		},
+		{
+			name:  "Test with Misspelled FileName",
+			src:   "alpah.delta",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			want:  [][]string{{}},
+			err:   errors.New("error: folder does not exist, did you mean \"alpha.delta\"?"),
+		},
+		{
+			name:  "Test with File Name in Different Org",
+			src:   "foxtrot",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			want:  [][]string{{}},
+			err:   errors.New("error: folder does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get := [][]string{}
+			opts := tt.opts
+
+			for {
+				page, err := f.GetAllChildFoldersWithOptions(tt.orgID, tt.src, opts)
+				if tt.err != nil && (err == nil || err.Error() != tt.err.Error()) {
+					t.Errorf("GetAllChildFoldersWithOptions() = %v, want %v for error", err, tt.err)
+				} else if tt.err == nil && err != nil {
+					t.Fatalf("GetAllChildFoldersWithOptions() = %v, want nil for error", err)
+				}
+
+				paths := []string{}
+				for _, f := range page.Folders {
+					paths = append(paths, f.Paths)
+				}
+				get = append(get, paths)
+
+				if page.NextCursor == "" {
+					break
+				}
+				opts.Cursor = page.NextCursor
+			}
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetAllChildFoldersWithOptions() = %v, want %v", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_ChildFolders(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name     string
+		src      string
+		maxDepth int
+		limit    int
+		want     []string
+	}{
+		{
+			name: "Same order as GetAllChildFolders",
+			src:  "alpha",
+			want: []string{"alpha.bravo", "alpha.bravo.charlie", "alpha.delta", "alpha.delta.echo"},
+		},
+		{
+			name:     "Limited depth",
+			src:      "alpha",
+			maxDepth: 1,
+			want:     []string{"alpha.bravo", "alpha.delta"},
+		},
+		{
+			name:  "Stopping early",
+			src:   "alpha",
+			limit: 3,
+			want:  []string{"alpha.bravo", "alpha.bravo.charlie", "alpha.delta"},
+		},
+		{
+			name: "No children",
+			src:  "golf",
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			seq, err := f.ChildFolders(uuid.FromStringOrNil(folder.DefaultOrgID), tt.src, tt.maxDepth)
+			if err != nil {
+				t.Fatalf("ChildFolders() = %v, want nil for error", err)
+			}
+
+			get := []string{}
+			for child := range seq {
+				get = append(get, child.Paths)
+				if len(get) == tt.limit {
+					break
+				}
+			}
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("ChildFolders() = %v, want %v", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_GetFoldersByOrgIDWithFilter(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	day := func(d int) time.Time {
+		return time.Date(2024, time.January, d, 0, 0, 0, 0, time.UTC)
+	}
+	folders := []folder.Folder{
+		{
+			Name:      "alpha",
+			OrgId:     orgID,
+			Paths:     "alpha",
+			Metadata:  map[string]string{"owner": "sam", "colour": "red"},
+			CreatedAt: day(1),
+			UpdatedAt: day(5),
+		},
+		{
+			Name:      "bravo",
+			OrgId:     orgID,
+			Paths:     "alpha.bravo",
+			Metadata:  map[string]string{"owner": "kim"},
+			CreatedAt: day(2),
+			UpdatedAt: day(2),
+		},
+		{
+			Name:  "charlie",
+			OrgId: orgID,
+			Paths: "charlie",
+		},
+		{
+			Name:     "delta",
+			OrgId:    uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7"),
+			Paths:    "delta",
+			Metadata: map[string]string{"owner": "sam"},
+		},
+	}
+	tests := [...]struct {
+		name   string
+		orgID  uuid.UUID
+		filter folder.FolderFilter
+		want   []folder.Folder
+	}{
+		{
+			name:   "Empty filter",
+			orgID:  orgID,
+			filter: folder.FolderFilter{},
+			want:   folders[:3],
+		},
+		{
+			name:  "Metadata value",
+			orgID: orgID,
+			filter: folder.FolderFilter{
+				Metadata: map[string]string{"owner": "sam"},
+			},
+			want: folders[:1],
+		},
+		{
+			name:  "Several metadata values",
+			orgID: orgID,
+			filter: folder.FolderFilter{
+				Metadata: map[string]string{"owner": "kim", "colour": "red"},
+			},
+			want: []folder.Folder{},
+		},
+		{
+			name:  "Created after",
+			orgID: orgID,
+			filter: folder.FolderFilter{
+				CreatedAfter: day(1),
+			},
+			want: folders[1:2],
+		},
+		{
+			name:  "Updated between",
+			orgID: orgID,
+			filter: folder.FolderFilter{
+				UpdatedAfter:  day(1),
+				UpdatedBefore: day(3),
+			},
+			want: folders[1:2],
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			want:  []folder.Folder{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(folders)
+			get := f.GetFoldersByOrgIDWithFilter(tt.orgID, tt.filter)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetFoldersByOrgIDWithFilter() = %v, want %v", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_Metadata_NotShared(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	folders := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha", Metadata: map[string]string{"owner": "sam"}},
+	}
+	f := folder.NewDriver(folders)
+
+	// Changing the metadata of a folder given to the driver,
+	// or returned by it, leaves the folder in the driver alone
+	folders[0].Metadata["owner"] = "kim"
+	f.GetFoldersByOrgID(orgID)[0].Metadata["owner"] = "lee"
+
+	get := f.GetFoldersByOrgID(orgID)[0].Metadata
+	if want := map[string]string{"owner": "sam"}; !reflect.DeepEqual(get, want) {
+		t.Errorf("GetFoldersByOrgID() metadata = %v, want %v", get, want)
+	}
+}
+
+// idFolders has two folders named bravo, which can
+// only be told apart by their ID
+var idFolders = []folder.Folder{
+	{
+		ID:    uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a01"),
+		Name:  "alpha",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha",
+	},
+	{
+		ID:    uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a02"),
+		Name:  "bravo",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "alpha.bravo",
+	},
+	{
+		ID:    uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a03"),
+		Name:  "golf",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "golf",
+	},
+	{
+		ID:    uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a04"),
+		Name:  "bravo",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "golf.bravo",
+	},
+}
+
+func Test_folder_LookupByID(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(idFolders)
+
+	for _, want := range idFolders {
+		parent, err := f.GetParent(orgID, want.ID.String())
+		if want.Paths == want.Name {
+			checkNotFound(t, "GetParent()", err, errors.New("error: parent folder does not exist"))
+			continue
+		}
+		if err != nil {
+			t.Fatalf("GetParent() = %v, want nil for error", err)
+		}
+		if !strings.HasPrefix(want.Paths, parent.Paths+".") {
+			t.Errorf("GetParent(%s) = %v, want the parent of %s", want.ID, parent, want.Paths)
+		}
+	}
+
+	get := f.GetAllChildFolders(orgID, idFolders[2].ID.String())
+	want := []folder.Folder{idFolders[3]}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAllChildFolders() = %v, want %v", get, want)
+	}
+
+	get = f.GetAllChildFolders(orgID, uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4aff").String())
+	if !reflect.DeepEqual(get, []folder.Folder{}) {
+		t.Errorf("GetAllChildFolders() = %v, want no folders for an unknown ID", get)
+	}
+}
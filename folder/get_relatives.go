@@ -0,0 +1,88 @@
+package folder
+
+import (
+	"github.com/gofrs/uuid"
+)
+
+// NodeFolders returns the Folders contained in 'fileNodes'
+func NodeFolders(fileNodes []*FileNode) []Folder {
+	folders := []Folder{}
+	for _, fileNode := range fileNodes {
+		folders = append(folders, fileNode.file.clone())
+	}
+
+	return folders
+}
+
+// GetAncestors returns the Folders above the folder with
+// 'path', following parent links up to its root, ordered
+// from the root down to the immediate parent
+func (f *driver) GetAncestors(orgID uuid.UUID, path string) ([]Folder, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	ancestors := []Folder{}
+	for parent := fileNode.parent; parent != nil; parent = parent.parent {
+		ancestors = append(ancestors, parent.file.clone())
+	}
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+
+	return ancestors, nil
+}
+
+// GetParent returns the Folder immediately above the folder
+// with 'path', or a NotFoundError if it is a root folder
+func (f *driver) GetParent(orgID uuid.UUID, path string) (Folder, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return Folder{}, err
+	}
+
+	if fileNode.parent == nil {
+		return Folder{}, &NotFoundError{Kind: KindParent, OrgID: orgID, Ref: path}
+	}
+
+	return fileNode.parent.file.clone(), nil
+}
+
+// GetSiblings returns the Folders which share a parent with
+// the folder with 'path'. The siblings of a root folder are
+// the other root folders of its organization.
+func (f *driver) GetSiblings(orgID uuid.UUID, path string) ([]Folder, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	var candidates []*FileNode
+	if fileNode.parent != nil {
+		candidates = fileNode.parent.children
+	} else {
+		candidates = FindRootNodes(f.orgs[orgID].folders)
+	}
+
+	siblings := []Folder{}
+	for _, sibling := range candidates {
+		if sibling != fileNode {
+			siblings = append(siblings, sibling.file.clone())
+		}
+	}
+
+	return siblings, nil
+}
+
+// GetImmediateChildren returns the Folders one level below
+// the folder with 'path', unlike GetChildren which returns
+// every folder below it
+func (f *driver) GetImmediateChildren(orgID uuid.UUID, path string) ([]Folder, error) {
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	return NodeFolders(fileNode.children), nil
+}
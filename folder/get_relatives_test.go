@@ -0,0 +1,270 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_GetAncestors(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		want  []folder.Folder
+		err   error
+	}{
+		{
+			name:  "Nested folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.bravo.charlie",
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "bravo",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.bravo",
+				},
+			},
+		},
+		{
+			name:  "Root folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "golf",
+			want:  []folder.Folder{},
+		},
+		{
+			name:  "Folder in a different org",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "foxtrot",
+			want:  []folder.Folder{},
+			err:   errors.New("error: folder does not exist"),
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			path:  "alpha",
+			want:  []folder.Folder{},
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get, err := f.GetAncestors(tt.orgID, tt.path)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetAncestors() = %v, want %v", get, tt.want)
+			}
+			checkNotFound(t, "GetAncestors()", err, tt.err)
+		})
+	}
+}
+
+func Test_folder_GetAncestors_SameNames(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(append(append([]folder.Folder{}, idFolders...),
+		folder.Folder{Name: "kilo", OrgId: orgID, Paths: "golf.bravo.kilo"}))
+
+	// kilo is linked to the bravo at its parent's path,
+	// not the first folder named bravo
+	get, err := f.GetAncestors(orgID, "golf.bravo.kilo")
+	if err != nil {
+		t.Fatalf("GetAncestors() = %v, want nil for error", err)
+	}
+	if want := []folder.Folder{idFolders[2], idFolders[3]}; !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAncestors() = %v, want %v", get, want)
+	}
+	if violations := f.CheckInvariants(orgID); len(violations) > 0 {
+		t.Errorf("CheckInvariants() = %v, want none", violations)
+	}
+}
+
+func Test_folder_GetParent(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		want  folder.Folder
+		err   error
+	}{
+		{
+			name:  "Nested folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.delta.echo",
+			want: folder.Folder{
+				Name:  "delta",
+				OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+				Paths: "alpha.delta",
+			},
+		},
+		{
+			name:  "Root folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			err:   errors.New("error: parent folder does not exist"),
+		},
+		{
+			name:  "Invalid path",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.echo",
+			err:   errors.New("error: folder does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get, err := f.GetParent(tt.orgID, tt.path)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetParent() = %v, want %v", get, tt.want)
+			}
+			checkNotFound(t, "GetParent()", err, tt.err)
+		})
+	}
+}
+
+func Test_folder_GetSiblings(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		want  []folder.Folder
+		err   error
+	}{
+		{
+			name:  "Nested folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.bravo",
+			want: []folder.Folder{
+				{
+					Name:  "delta",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.delta",
+				},
+			},
+		},
+		{
+			name:  "Only child",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.bravo.charlie",
+			want:  []folder.Folder{},
+		},
+		{
+			name:  "Root folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "golf",
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+			},
+		},
+		{
+			name:  "Invalid path",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "hotel",
+			want:  []folder.Folder{},
+			err:   errors.New("error: folder does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get, err := f.GetSiblings(tt.orgID, tt.path)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetSiblings() = %v, want %v", get, tt.want)
+			}
+			checkNotFound(t, "GetSiblings()", err, tt.err)
+		})
+	}
+}
+
+func Test_folder_GetImmediateChildren(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		want  []folder.Folder
+		err   error
+	}{
+		{
+			name:  "Folder with grandchildren",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			want: []folder.Folder{
+				{
+					Name:  "bravo",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.bravo",
+				},
+				{
+					Name:  "delta",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.delta",
+				},
+			},
+		},
+		{
+			name:  "Leaf folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "golf",
+			want:  []folder.Folder{},
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			path:  "alpha",
+			want:  []folder.Folder{},
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			get, err := f.GetImmediateChildren(tt.orgID, tt.path)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("GetImmediateChildren() = %v, want %v", get, tt.want)
+			}
+			checkNotFound(t, "GetImmediateChildren()", err, tt.err)
+		})
+	}
+}
+
+// checkNotFound reports an error if 'err' does not have the
+// same message as 'want', or is not a folder.NotFoundError
+func checkNotFound(t *testing.T, method string, err error, want error) {
+	t.Helper()
+	if want != nil && err == nil {
+		t.Errorf("%s = nil, want %v for error", method, want)
+	} else if want == nil && err != nil {
+		t.Errorf("%s = %v, want nil for error", method, err)
+	} else if want != nil && err != nil {
+		if want.Error() != err.Error() {
+			t.Errorf("%s = %v\n want %v for error", method, err, want)
+		}
+		if !errors.Is(err, folder.ErrNotFound) {
+			t.Errorf("%s = %v, want a NotFoundError", method, err)
+		}
+	}
+}
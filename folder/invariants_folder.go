@@ -0,0 +1,178 @@
+package folder
+
+import (
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+// ViolationKind is which invariant of the folders of a
+// driver a Violation breaks
+type ViolationKind string
+
+const (
+	ViolationPath         ViolationKind = "path"
+	ViolationLink         ViolationKind = "link"
+	ViolationCycle        ViolationKind = "cycle"
+	ViolationOrganization ViolationKind = "organization"
+	ViolationIndex        ViolationKind = "index"
+	ViolationDescendants  ViolationKind = "descendants"
+)
+
+// Violation is a folder of a driver which breaks an
+// invariant its operations are meant to keep
+type Violation struct {
+	Kind  ViolationKind `json:"kind"`
+	OrgID uuid.UUID     `json:"org_id"`
+	Name  string        `json:"name"`
+	Paths string        `json:"paths"`
+	// Detail describes how the invariant is broken
+	Detail string `json:"detail"`
+}
+
+// String returns the violation as a line of text
+func (v Violation) String() string {
+	return fmt.Sprintf("%-12s %s %s: %s", v.Kind, v.OrgID, v.Paths, v.Detail)
+}
+
+// CheckInvariants returns every way the folders of the
+// organization with 'orgID' break the invariants kept by the
+// driver, which is none for an organization with no folders:
+//   - the path of a folder is the path of its parent followed
+//     by its name, and the path of a root folder ends in its name
+//   - a folder is one of the children of its parent, once,
+//     and the parent of each of its children
+//   - following parents never leads back to a folder
+//   - parents and children are folders of the organization
+//   - no two folders have the same path
+//   - the index finds every folder by its path and ID
+//   - the descendant counts kept by incremental stats are right,
+//     which is only checked once every other invariant holds
+func (f *driver) CheckInvariants(orgID uuid.UUID) []Violation {
+	violations := []Violation{}
+	org, exists := f.orgs[orgID]
+	if !exists {
+		return violations
+	}
+
+	report := func(kind ViolationKind, fileNode *FileNode, format string, args ...any) {
+		violations = append(violations, Violation{
+			Kind:   kind,
+			OrgID:  orgID,
+			Name:   fileNode.file.Name,
+			Paths:  fileNode.file.Paths,
+			Detail: fmt.Sprintf(format, args...),
+		})
+	}
+
+	members := map[*FileNode]bool{}
+	byPath := map[string]*FileNode{}
+	for _, fileNode := range org.folders {
+		members[fileNode] = true
+	}
+
+	for _, fileNode := range org.folders {
+		if fileNode.file.OrgId != orgID {
+			report(ViolationOrganization, fileNode, "folder belongs to organization %s", fileNode.file.OrgId)
+		}
+
+		parent := fileNode.parent
+		if parent == nil {
+			sections := f.codec.Split(fileNode.file.Paths)
+			if sections[len(sections)-1] != fileNode.file.Name {
+				report(ViolationPath, fileNode, "root folder path does not end in %q", fileNode.file.Name)
+			}
+		} else {
+			if want := f.codec.Append(parent.file.Paths, fileNode.file.Name); fileNode.file.Paths != want {
+				report(ViolationPath, fileNode, "path should be %s", want)
+			}
+			if !members[parent] {
+				report(ViolationOrganization, fileNode, "parent %s is not a folder of the organization", parent.file.Paths)
+			}
+			if count := countChild(parent, fileNode); count != 1 {
+				report(ViolationLink, fileNode, "folder is a child of its parent %s %d times", parent.file.Paths, count)
+			}
+		}
+
+		for _, childNode := range fileNode.children {
+			if childNode.parent != fileNode {
+				report(ViolationLink, fileNode, "child %s has a different parent", childNode.file.Paths)
+			}
+			if !members[childNode] {
+				report(ViolationOrganization, fileNode, "child %s is not a folder of the organization", childNode.file.Paths)
+			}
+		}
+
+		if inCycle(fileNode, len(org.folders)) {
+			report(ViolationCycle, fileNode, "following parents leads back to the folder")
+		}
+
+		// The index holds a single folder for each path, so
+		// a folder sharing its path is only reported once
+		if _, exists := byPath[fileNode.file.Paths]; exists {
+			report(ViolationPath, fileNode, "another folder has the same path")
+			continue
+		}
+		byPath[fileNode.file.Paths] = fileNode
+
+		if org.index != nil {
+			if indexed := org.index.Lookup(fileNode.file.Paths); indexed == nil {
+				report(ViolationIndex, fileNode, "path is not indexed")
+			} else if indexed != fileNode {
+				report(ViolationIndex, fileNode, "path is indexed to another folder")
+			}
+			if id := fileNode.file.ID; id != uuid.Nil && org.index.LookupID(id) != fileNode {
+				report(ViolationIndex, fileNode, "ID %s is not indexed to the folder", id)
+			}
+		}
+	}
+
+	if org.index != nil {
+		for paths, fileNode := range org.index.byPath {
+			if fileNode.file.Paths != paths || !members[fileNode] {
+				report(ViolationIndex, fileNode, "indexed under the stale path %s", paths)
+			}
+		}
+	}
+
+	// Counting descendants by walking children never ends
+	// if the links between folders are broken
+	if f.incrementalStats && len(violations) == 0 {
+		for _, fileNode := range org.folders {
+			if want := CollectStats([]*FileNode{fileNode}).Descendants; fileNode.descendants != want {
+				report(ViolationDescendants, fileNode, "descendant count is %d, should be %d", fileNode.descendants, want)
+			}
+		}
+	}
+
+	return violations
+}
+
+// countChild returns the number of times 'childNode'
+// is one of the children of 'parentNode'
+func countChild(parentNode *FileNode, childNode *FileNode) int {
+	count := 0
+	for _, c := range parentNode.children {
+		if c == childNode {
+			count++
+		}
+	}
+
+	return count
+}
+
+// inCycle returns whether following the parents of
+// 'fileNode' leads back to it, giving up after 'limit'
+// parents, which is more than an organization can have
+// without a cycle
+func inCycle(fileNode *FileNode, limit int) bool {
+	parent := fileNode.parent
+	for i := 0; parent != nil && i < limit; i++ {
+		if parent == fileNode {
+			return true
+		}
+		parent = parent.parent
+	}
+
+	return false
+}
@@ -0,0 +1,136 @@
+package folder_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_CheckInvariants(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	tests := [...]struct {
+		name    string
+		folders []folder.Folder
+		want    []string
+	}{
+		{
+			name:    "Sound folders",
+			folders: exampleFolders,
+			want:    []string{},
+		},
+		{
+			name: "Path not ending in the name",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.charlie"},
+				{Name: "golf", OrgId: orgID, Paths: "hotel"},
+			},
+			want: []string{
+				"path alpha.charlie: path should be alpha.bravo",
+				`path hotel: root folder path does not end in "golf"`,
+			},
+		},
+		{
+			name: "Cycle",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "bravo.alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+			},
+			want: []string{
+				"path bravo.alpha: path should be alpha.bravo.alpha",
+				"cycle bravo.alpha: following parents leads back to the folder",
+				"path alpha.bravo: path should be bravo.alpha.bravo",
+				"cycle alpha.bravo: following parents leads back to the folder",
+			},
+		},
+		{
+			name: "Duplicate paths",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+			},
+			want: []string{
+				"path alpha.bravo: another folder has the same path",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(append([]folder.Folder{}, tt.folders...))
+
+			get := []string{}
+			for _, v := range f.CheckInvariants(orgID) {
+				get = append(get, fmt.Sprintf("%s %s: %s", v.Kind, v.Paths, v.Detail))
+			}
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("CheckInvariants() = %q, want %q", get, tt.want)
+			}
+		})
+	}
+}
+
+// Fuzz_folder_Operations moves and creates folders chosen by
+// 'ops', three bytes at a time, in generated organizations,
+// checking the invariants of every organization after each.
+// Created folders take the name of another folder, so names
+// are repeated across and within organizations.
+func Fuzz_folder_Operations(f *testing.F) {
+	f.Add(int64(1), false, []byte{0, 1, 2, 3, 4, 5, 6, 7, 8})
+	f.Add(int64(2), true, []byte{0, 9, 3, 1, 20, 4, 2, 7, 30, 3, 5, 1})
+	f.Add(int64(3), false, []byte{2, 0, 0, 2, 1, 1, 0, 2, 40, 1, 3, 41})
+
+	f.Fuzz(func(t *testing.T, seed int64, slash bool, ops []byte) {
+		cfg := folder.DefaultGeneratorConfig()
+		cfg.Seed = seed
+		cfg.Depth = folder.Distribution{Min: 1, Max: 4}
+		folders, err := folder.GenerateDataWithConfig(cfg)
+		if err != nil {
+			t.Fatalf("GenerateDataWithConfig() = %v, want nil for error", err)
+		}
+
+		opts := []folder.DriverOption{folder.WithIncrementalStats()}
+		if slash {
+			folders = folder.ConvertFolders(folders, folder.DefaultPathCodec, folder.SlashPathCodec)
+			opts = append(opts, folder.WithPathCodec(folder.SlashPathCodec))
+		}
+		d := folder.NewDriver(folders, opts...)
+
+		orgIDs := []uuid.UUID{}
+		for _, f := range folders {
+			if len(orgIDs) == 0 || orgIDs[len(orgIDs)-1] != f.OrgId {
+				orgIDs = append(orgIDs, f.OrgId)
+			}
+		}
+
+		for i := 0; i+2 < len(ops); i += 3 {
+			orgID := orgIDs[int(ops[i]/3)%len(orgIDs)]
+			orgFolders := d.GetFoldersByOrgID(orgID)
+			a := orgFolders[int(ops[i+1])%len(orgFolders)]
+			b := orgFolders[int(ops[i+2])%len(orgFolders)]
+
+			// Operations which are not allowed are expected to
+			// fail without breaking anything
+			op := ""
+			switch ops[i] % 3 {
+			case 0, 1:
+				op = fmt.Sprintf("MoveFolder(%s, %s)", a.Paths, b.Paths)
+				_, _ = d.MoveFolder(a.ID.String(), b.ID.String())
+			case 2:
+				op = fmt.Sprintf("CreateFolder(%s, %s)", b.Name, a.Paths)
+				_, _ = d.CreateFolder(orgID, b.Name, a.ID.String())
+			}
+
+			for _, orgID := range orgIDs {
+				if violations := d.CheckInvariants(orgID); len(violations) > 0 {
+					t.Fatalf("CheckInvariants() = %v after %s, want none", violations, op)
+				}
+			}
+		}
+	})
+}
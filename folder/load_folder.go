@@ -0,0 +1,144 @@
+package folder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// LoadOptions configures how a JSON array of folders is
+// streamed by DecodeFolders and NewDriverFromReader
+type LoadOptions struct {
+	// OnProgress, if set, is called after every folder that has
+	// been loaded, with the number of folders loaded so far
+	OnProgress func(loaded int)
+
+	// OnError, if set, is called for every record that could not
+	// be decoded, and that record is skipped. Returning a non-nil
+	// error stops the load. If OnError is nil, the first bad
+	// record stops the load.
+	OnError func(err *RecordError) error
+}
+
+// RecordError describes a single record of a JSON array
+// of folders that could not be decoded
+type RecordError struct {
+	Index int
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("error: record %d: %s", e.Index, strings.TrimPrefix(e.Err.Error(), "error: "))
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeFolders reads a JSON array of folders from 'r' one
+// record at a time, calling 'fn' for each folder decoded, so
+// the whole array never has to be held in memory at once
+func DecodeFolders(r io.Reader, opts LoadOptions, fn func(Folder)) error {
+	return decodeFolders(r, opts, nil, DefaultPathCodec, fn)
+}
+
+// decodeFolders is DecodeFolders, also checking every name
+// in each folder with 'validate' if it is not nil, splitting
+// their paths using 'codec'
+func decodeFolders(r io.Reader, opts LoadOptions, validate NameValidator, codec PathCodec, fn func(Folder)) error {
+	dec := json.NewDecoder(r)
+
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("error: reading folders: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return errors.New("error: folders must be a JSON array")
+	}
+
+	loaded := 0
+	for index := 0; dec.More(); index++ {
+		// A syntax error leaves the decoder unable to find the start
+		// of the next record, so only errors in the contents of a
+		// record can be skipped
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return &RecordError{Index: index, Err: err}
+		}
+
+		f, err := decodeFolder(raw)
+		if err == nil && validate != nil {
+			f, err = normalizeFolder(f, validate, codec)
+		}
+		if err != nil {
+			recordErr := &RecordError{Index: index, Err: err}
+			if opts.OnError == nil {
+				return recordErr
+			}
+			if err := opts.OnError(recordErr); err != nil {
+				return err
+			}
+			continue
+		}
+
+		fn(f)
+		loaded++
+		if opts.OnProgress != nil {
+			opts.OnProgress(loaded)
+		}
+	}
+
+	if _, err := dec.Token(); err != nil {
+		return fmt.Errorf("error: reading folders: %w", err)
+	}
+
+	return nil
+}
+
+// decodeFolder unmarshals a single record, and checks
+// it has the fields needed to place it in a tree
+func decodeFolder(raw json.RawMessage) (Folder, error) {
+	f := Folder{}
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return Folder{}, err
+	}
+	if f.Name == "" {
+		return Folder{}, errors.New("folder has no name")
+	}
+	if f.Paths == "" {
+		return Folder{}, errors.New("folder has no paths")
+	}
+	if f.OrgId == uuid.Nil {
+		return Folder{}, errors.New("folder has no org_id")
+	}
+
+	return f, nil
+}
+
+// NewDriverFromReader returns an IDriver built from a JSON
+// array of folders read from 'r', adding each folder to its
+// Organization as soon as it is decoded. A folder with a name
+// the driver does not allow is a record that could not be
+// decoded.
+func NewDriverFromReader(r io.Reader, opts LoadOptions, driverOpts ...DriverOption) (IDriver, error) {
+	d := applyOptions(driverOpts)
+
+	orgs := map[uuid.UUID]Organization{}
+	err := decodeFolders(r, opts, d.validateName, d.codec, func(f Folder) {
+		AddFileNode(f, orgs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	LinkOrgs(orgs, d.codec)
+	if err := d.adoptOrphans(orgs); err != nil {
+		return nil, err
+	}
+	d.setOrgs(orgs)
+
+	return d, nil
+}
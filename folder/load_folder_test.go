@@ -0,0 +1,262 @@
+package folder_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_DecodeFolders(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name       string
+		input      string
+		skipErrors bool
+		want       []folder.Folder
+		errIndexes []int
+		err        error
+	}{
+		{
+			name: "Valid array",
+			input: `[
+				{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+				{"name": "beta", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.beta"}
+			]`,
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "beta",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.beta",
+				},
+			},
+		},
+		{
+			name: "Records with and without an id",
+			input: `[
+				{"id": "9a3f6d2e-4b1c-4e8a-9f0d-2c5b7e1a3d4f", "name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+				{"name": "beta", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.beta"}
+			]`,
+			want: []folder.Folder{
+				{
+					ID:    uuid.FromStringOrNil("9a3f6d2e-4b1c-4e8a-9f0d-2c5b7e1a3d4f"),
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "beta",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.beta",
+				},
+			},
+		},
+		{
+			name:  "Empty array",
+			input: `[]`,
+			want:  []folder.Folder{},
+		},
+		{
+			name:  "Not an array",
+			input: `{"name": "alpha"}`,
+			want:  []folder.Folder{},
+			err:   errors.New("error: folders must be a JSON array"),
+		},
+		{
+			name: "Bad record stops the load",
+			input: `[
+				{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+				{"name": "beta", "org_id": "not-a-uuid", "paths": "alpha.beta"},
+				{"name": "gamma", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.gamma"}
+			]`,
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+			},
+			err: errors.New("error: record 1: uuid: incorrect UUID length 10 in string \"not-a-uuid\""),
+		},
+		{
+			name: "Bad records are skipped",
+			input: `[
+				{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+				{"name": "beta", "org_id": "not-a-uuid", "paths": "alpha.beta"},
+				{"org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.delta"},
+				{"name": "gamma", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.gamma"}
+			]`,
+			skipErrors: true,
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "gamma",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha.gamma",
+				},
+			},
+			errIndexes: []int{1, 2},
+		},
+		{
+			name: "Truncated array",
+			input: `[
+				{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+				{"name": "beta", "org_id"`,
+			want: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+			},
+			err: errors.New("error: record 1: unexpected EOF"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := []folder.Folder{}
+			errIndexes := []int{}
+			progress := 0
+
+			opts := folder.LoadOptions{
+				OnProgress: func(loaded int) {
+					progress = loaded
+				},
+			}
+			if tt.skipErrors {
+				opts.OnError = func(err *folder.RecordError) error {
+					errIndexes = append(errIndexes, err.Index)
+					return nil
+				}
+			}
+
+			err := folder.DecodeFolders(strings.NewReader(tt.input), opts, func(f folder.Folder) {
+				get = append(get, f)
+			})
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("DecodeFolders() = %v, want %v for output", get, tt.want)
+			}
+			if progress != len(tt.want) {
+				t.Errorf("DecodeFolders() reported %d loaded, want %d", progress, len(tt.want))
+			}
+			if tt.skipErrors && !reflect.DeepEqual(errIndexes, tt.errIndexes) {
+				t.Errorf("DecodeFolders() reported errors for %v, want %v", errIndexes, tt.errIndexes)
+			}
+
+			if tt.err != nil && err == nil {
+				t.Errorf("DecodeFolders() = nil, want %v for error", tt.err)
+			} else if tt.err == nil && err != nil {
+				t.Errorf("DecodeFolders() = %v, want nil for error", err)
+			} else if tt.err != nil && err != nil && tt.err.Error() != err.Error() {
+				t.Errorf("DecodeFolders() = %v\n want %v for error", err, tt.err)
+			}
+		})
+	}
+}
+
+func Test_folder_NewDriverFromReader(t *testing.T) {
+	t.Parallel()
+	input := `[
+		{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+		{"name": "beta", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.beta"},
+		{"name": "gamma", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.beta.gamma"}
+	]`
+
+	f, err := folder.NewDriverFromReader(strings.NewReader(input), folder.LoadOptions{})
+	if err != nil {
+		t.Fatalf("NewDriverFromReader() = %v, want nil for error", err)
+	}
+
+	get := f.GetAllChildFolders(uuid.FromStringOrNil(folder.DefaultOrgID), "alpha")
+	want := []folder.Folder{
+		{
+			Name:  "beta",
+			OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+			Paths: "alpha.beta",
+		},
+		{
+			Name:  "gamma",
+			OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+			Paths: "alpha.beta.gamma",
+		},
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAllChildFolders() = %v, want %v", get, want)
+	}
+}
+
+func Test_folder_Folder_MarshalJSON(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name string
+		f    folder.Folder
+		want string
+	}{
+		{
+			name: "Folder without an ID",
+			f: folder.Folder{
+				Name:  "alpha",
+				OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+				Paths: "alpha",
+			},
+			want: `{"name":"alpha","org_id":"` + folder.DefaultOrgID + `","paths":"alpha"}`,
+		},
+		{
+			name: "Folder with an ID",
+			f: folder.Folder{
+				ID:    uuid.FromStringOrNil("9a3f6d2e-4b1c-4e8a-9f0d-2c5b7e1a3d4f"),
+				Name:  "alpha",
+				OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+				Paths: "alpha",
+			},
+			want: `{"id":"9a3f6d2e-4b1c-4e8a-9f0d-2c5b7e1a3d4f","name":"alpha","org_id":"` + folder.DefaultOrgID + `","paths":"alpha"}`,
+		},
+		{
+			name: "Folder with metadata and timestamps",
+			f: folder.Folder{
+				Name:      "alpha",
+				OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+				Paths:     "alpha",
+				Metadata:  map[string]string{"owner": "sam", "colour": "red"},
+				CreatedAt: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+				UpdatedAt: time.Date(2024, time.February, 3, 4, 5, 6, 0, time.UTC),
+			},
+			want: `{"name":"alpha","org_id":"` + folder.DefaultOrgID + `","paths":"alpha",` +
+				`"metadata":{"colour":"red","owner":"sam"},` +
+				`"created_at":"2024-01-02T03:04:05Z","updated_at":"2024-02-03T04:05:06Z"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get, err := json.Marshal(tt.f)
+			if err != nil {
+				t.Fatalf("MarshalJSON() = %v, want nil for error", err)
+			}
+			if string(get) != tt.want {
+				t.Errorf("MarshalJSON() = %s, want %s", get, tt.want)
+			}
+
+			again := folder.Folder{}
+			if err := json.Unmarshal(get, &again); err != nil || !reflect.DeepEqual(again, tt.f) {
+				t.Errorf("UnmarshalJSON() = %v, want %v", again, tt.f)
+			}
+		})
+	}
+}
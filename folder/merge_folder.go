@@ -0,0 +1,453 @@
+package folder
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// ConflictKind is why a change could not be merged
+type ConflictKind string
+
+const (
+	// ConflictMoveMove is both sides moving a folder
+	// to different parents
+	ConflictMoveMove ConflictKind = "move/move"
+	// ConflictRenameRename is both sides renaming a folder
+	// to different names
+	ConflictRenameRename ConflictKind = "rename/rename"
+	// ConflictAddAdd is both sides adding a folder with the
+	// same name at different paths
+	ConflictAddAdd ConflictKind = "add/add"
+	// ConflictDeleteEdit is one side removing a folder that
+	// the other side moved, renamed or put folders into
+	ConflictDeleteEdit ConflictKind = "delete/edit"
+	// ConflictMissingParent is a folder added or moved into
+	// a folder which no longer exists once both sides merge
+	ConflictMissingParent ConflictKind = "missing parent"
+	// ConflictCycle is a move which puts a folder below
+	// itself once the other side's moves are made
+	ConflictCycle ConflictKind = "cycle"
+)
+
+// MergeConflict is a change which was left out of a merge
+type MergeConflict struct {
+	Kind  ConflictKind `json:"kind"`
+	OrgId uuid.UUID    `json:"org_id"`
+	// ID is the ID of the folder, if it has one
+	ID uuid.UUID `json:"id,omitempty"`
+	// Name is the name of the folder in the base set, or
+	// the name it was added with
+	Name string `json:"name"`
+	// Ours and Theirs describe what each side did to the
+	// folder, empty for a side which left it alone
+	Ours   string `json:"ours,omitempty"`
+	Theirs string `json:"theirs,omitempty"`
+}
+
+// MergeResult is the folders produced by Merge, and the
+// changes which could not be merged
+type MergeResult struct {
+	Folders   []Folder        `json:"folders"`
+	Conflicts []MergeConflict `json:"conflicts"`
+}
+
+// mergeSide is the changes one side made to the base set,
+// keyed by the folder in the base set they apply to
+type mergeSide struct {
+	moves   map[folderKey]FolderChange
+	renames map[folderKey]FolderChange
+	removes map[folderKey]FolderChange
+	// adds is the keys of the added folders in path
+	// order, so parents are added first
+	adds    []folderKey
+	addKeys map[folderKey]FolderChange
+	// renamedFrom maps the new key of a folder renamed
+	// without an ID back to its key in the base set
+	renamedFrom map[folderKey]folderKey
+	// folders is the folders of the side by their key, and
+	// byPath is the key of the first folder at each path
+	folders map[folderKey]Folder
+	byPath  map[pathKey]folderKey
+	keyer   keyer
+}
+
+// newMergeSide returns the changes which turn 'base' into
+// 'after', matching folders by the keys given by 'k'
+func newMergeSide(k keyer, base []Folder, after []Folder) mergeSide {
+	d := diffFolders(k, base, after, DiffOptions{InferRenames: true})
+	side := mergeSide{
+		moves:       map[folderKey]FolderChange{},
+		renames:     map[folderKey]FolderChange{},
+		removes:     map[folderKey]FolderChange{},
+		adds:        []folderKey{},
+		addKeys:     map[folderKey]FolderChange{},
+		renamedFrom: d.renamedFrom,
+		folders:     d.after,
+		byPath:      d.afterPaths,
+		keyer:       k,
+	}
+
+	for i, c := range d.diff.Changes {
+		key := d.keys[i]
+		switch c.Kind {
+		case ChangeMoved:
+			side.moves[key] = c
+		case ChangeRenamed:
+			side.renames[key] = c
+		case ChangeRemoved:
+			side.removes[key] = c
+		case ChangeAdded:
+			side.adds = append(side.adds, key)
+			side.addKeys[key] = c
+		}
+	}
+
+	return side
+}
+
+// parentKey returns the key in the base set of the folder
+// a change made by the side puts its folder into, and false
+// for a root folder
+func (side mergeSide) parentKey(c FolderChange) (folderKey, bool) {
+	key, ok := side.keyer.parentKey(side.byPath, Folder{OrgId: c.OrgId, Paths: c.NewPaths})
+	if baseKey, exists := side.renamedFrom[key]; exists {
+		key = baseKey
+	}
+
+	return key, ok
+}
+
+// describe returns what the side did to the folder with
+// 'key', or an empty string if it left it alone
+func (side mergeSide) describe(key folderKey) string {
+	if c, exists := side.moves[key]; exists {
+		return "moved to " + c.NewPaths
+	}
+	if c, exists := side.renames[key]; exists {
+		return "renamed to " + c.Name
+	}
+	if _, exists := side.removes[key]; exists {
+		return "removed"
+	}
+	if c, exists := side.addKeys[key]; exists {
+		return "added at " + c.NewPaths
+	}
+
+	return ""
+}
+
+// merger is the state of a merge in progress, as a tree of
+// FileNodes built from the base set
+type merger struct {
+	nodes map[folderKey]*FileNode
+	// names is the name of every folder in the base set,
+	// or the name it was added with
+	names map[folderKey]string
+	// keys is the key of every FileNode, which is its
+	// name in the base set if it was renamed
+	keys map[*FileNode]folderKey
+	// order is every FileNode in the order it is output
+	order   []*FileNode
+	removed map[*FileNode]bool
+	// kept is the folders one side removed which were
+	// kept because the other side edited them
+	kept      map[folderKey]bool
+	conflicts []MergeConflict
+}
+
+func (m *merger) conflict(kind ConflictKind, key folderKey, ours mergeSide, theirs mergeSide) {
+	m.conflicts = append(m.conflicts, MergeConflict{
+		Kind:   kind,
+		OrgId:  key.orgID,
+		ID:     key.id,
+		Name:   m.names[key],
+		Ours:   ours.describe(key),
+		Theirs: theirs.describe(key),
+	})
+}
+
+// parentNode returns the FileNode a change made by 'side'
+// puts its folder into, nil for a root folder, and whether
+// that FileNode still exists in the merge
+func (m *merger) parentNode(side mergeSide, c FolderChange) (*FileNode, bool) {
+	key, ok := side.parentKey(c)
+	if !ok {
+		return nil, true
+	}
+	parent, exists := m.nodes[key]
+	if !exists || m.removed[parent] {
+		return nil, false
+	}
+
+	return parent, true
+}
+
+// Merge applies the changes made by 'ours' and 'theirs' to
+// 'base', matching folders in the same way as DiffWithOptions
+// with InferRenames set, so the changes one side makes below
+// a folder without an ID follow it when the other renames it.
+// Changes which conflict are left out of both sides, so the
+// folder keeps its state in 'base', and reported. A move which
+// would put a folder below itself once our moves are made is
+// left out of their side, using the same check as MoveFolder.
+// Every folder in 'base' is kept unless a side removes it.
+func Merge(base []Folder, ours []Folder, theirs []Folder) MergeResult {
+	k := newKeyer(base, ours, theirs)
+	oursSide := newMergeSide(k, base, ours)
+	theirsSide := newMergeSide(k, base, theirs)
+
+	m := &merger{
+		nodes:     map[folderKey]*FileNode{},
+		names:     map[folderKey]string{},
+		keys:      map[*FileNode]folderKey{},
+		order:     []*FileNode{},
+		removed:   map[*FileNode]bool{},
+		kept:      map[folderKey]bool{},
+		conflicts: []MergeConflict{},
+	}
+	// The FileNodes of each organization are in the same
+	// order as its folders in 'base'
+	orgs := GenerateOrgs(base)
+	next := map[uuid.UUID]int{}
+	for _, f := range base {
+		fileNode := orgs[f.OrgId].folders[next[f.OrgId]]
+		next[f.OrgId]++
+		m.order = append(m.order, fileNode)
+
+		key := k.keyOf(f)
+		if _, exists := m.nodes[key]; !exists {
+			m.nodes[key] = fileNode
+			m.names[key] = f.Name
+			m.keys[fileNode] = key
+		}
+	}
+
+	moves, renames, removes := m.resolveEdits(oursSide, theirsSide)
+	adds := m.resolveAdds(oursSide, theirsSide, removes)
+
+	// Renames never change where a folder is, so they
+	// are made first
+	for _, r := range renames {
+		m.nodes[r.key].file.Name = r.change.Name
+	}
+
+	for _, a := range adds {
+		key := a.key
+		parent, ok := m.parentNode(a.side, a.change)
+		if !ok {
+			m.conflict(ConflictMissingParent, key, oursSide, theirsSide)
+			continue
+		}
+
+		fileNode := NewFileNode(a.side.folders[key])
+		if parent != nil {
+			fileNode.parent = parent
+			parent.children = append(parent.children, fileNode)
+		}
+		m.nodes[key] = fileNode
+		m.keys[fileNode] = key
+		m.order = append(m.order, fileNode)
+	}
+
+	for _, mv := range moves {
+		fileNode := m.nodes[mv.key]
+		parent, ok := m.parentNode(mv.side, mv.change)
+		if !ok {
+			m.conflict(ConflictMissingParent, mv.key, oursSide, theirsSide)
+			continue
+		}
+		if parent != nil && (parent == fileNode || CheckIsChild(fileNode, parent)) {
+			m.conflict(ConflictCycle, mv.key, oursSide, theirsSide)
+			continue
+		}
+
+		if fileNode.parent != nil {
+			fileNode.parent.children = RemoveChild(fileNode.parent, fileNode)
+		}
+		fileNode.parent = parent
+		if parent != nil {
+			parent.children = append(parent.children, fileNode)
+		}
+	}
+
+	m.applyRemoves(removes, oursSide, theirsSide)
+
+	return MergeResult{
+		Folders:   m.folders(),
+		Conflicts: m.conflicts,
+	}
+}
+
+// sideChange is a change to make during a merge, with
+// the side it came from
+type sideChange struct {
+	key    folderKey
+	change FolderChange
+	side   mergeSide
+}
+
+// resolveEdits returns the moves, renames and removals of
+// folders in the base set which do not conflict, with our
+// moves before theirs
+func (m *merger) resolveEdits(ours mergeSide, theirs mergeSide) ([]sideChange, []sideChange, map[folderKey]bool) {
+	keys := []folderKey{}
+	for _, side := range []mergeSide{ours, theirs} {
+		for _, changes := range []map[folderKey]FolderChange{side.moves, side.renames, side.removes} {
+			for key := range changes {
+				keys = append(keys, key)
+			}
+		}
+	}
+	slices.SortFunc(keys, compareKeys)
+	keys = slices.Compact(keys)
+
+	oursMoves, theirsMoves, renames := []sideChange{}, []sideChange{}, []sideChange{}
+	removes := map[folderKey]bool{}
+	for _, key := range keys {
+		ourMove, oursMoved := ours.moves[key]
+		theirMove, theirsMoved := theirs.moves[key]
+		ourRename, oursRenamed := ours.renames[key]
+		theirRename, theirsRenamed := theirs.renames[key]
+		_, oursRemoved := ours.removes[key]
+		_, theirsRemoved := theirs.removes[key]
+
+		if oursRemoved != theirsRemoved && (oursMoved || theirsMoved || oursRenamed || theirsRenamed) {
+			m.conflict(ConflictDeleteEdit, key, ours, theirs)
+			m.kept[key] = true
+			continue
+		}
+		if oursRemoved || theirsRemoved {
+			removes[key] = true
+			continue
+		}
+
+		// Both sides moving a folder into the same parent
+		// is the same change, whatever its path
+		moveConflict := false
+		if oursMoved && theirsMoved {
+			ourParent, _ := ours.parentKey(ourMove)
+			theirParent, _ := theirs.parentKey(theirMove)
+			moveConflict = ourParent != theirParent
+		}
+
+		switch {
+		case moveConflict:
+			m.conflict(ConflictMoveMove, key, ours, theirs)
+		case oursMoved:
+			oursMoves = append(oursMoves, sideChange{key: key, change: ourMove, side: ours})
+		case theirsMoved:
+			theirsMoves = append(theirsMoves, sideChange{key: key, change: theirMove, side: theirs})
+		}
+
+		switch {
+		case oursRenamed && theirsRenamed && ourRename.Name != theirRename.Name:
+			m.conflict(ConflictRenameRename, key, ours, theirs)
+		case oursRenamed:
+			renames = append(renames, sideChange{key: key, change: ourRename, side: ours})
+		case theirsRenamed:
+			renames = append(renames, sideChange{key: key, change: theirRename, side: theirs})
+		}
+	}
+
+	return append(oursMoves, theirsMoves...), renames, removes
+}
+
+// resolveAdds returns the folders added by either side which
+// do not conflict, in path order
+func (m *merger) resolveAdds(ours mergeSide, theirs mergeSide, removes map[folderKey]bool) []sideChange {
+	adds := []sideChange{}
+	for _, key := range ours.adds {
+		c := ours.addKeys[key]
+		m.names[key] = c.Name
+		if theirAdd, exists := theirs.addKeys[key]; exists && theirAdd.NewPaths != c.NewPaths {
+			m.conflict(ConflictAddAdd, key, ours, theirs)
+			continue
+		}
+		adds = append(adds, sideChange{key: key, change: c, side: ours})
+	}
+	for _, key := range theirs.adds {
+		c := theirs.addKeys[key]
+		if _, exists := m.names[key]; !exists {
+			m.names[key] = c.Name
+		}
+		if _, exists := ours.addKeys[key]; exists {
+			continue
+		}
+		adds = append(adds, sideChange{key: key, change: c, side: theirs})
+	}
+
+	slices.SortStableFunc(adds, func(a sideChange, b sideChange) int {
+		return ComparePaths(SplitPath(a.change.NewPaths), SplitPath(b.change.NewPaths))
+	})
+
+	return adds
+}
+
+// applyRemoves removes the folders with 'removes' keys, deepest
+// first. A folder which still has children that are not being
+// removed, because the other side moved or added them into it,
+// is kept and reported. The folders above it are kept too,
+// but not reported again.
+func (m *merger) applyRemoves(removes map[folderKey]bool, ours mergeSide, theirs mergeSide) {
+	keys := []folderKey{}
+	for key := range removes {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a folderKey, b folderKey) int {
+		if c := NodeDepth(m.nodes[b]) - NodeDepth(m.nodes[a]); c != 0 {
+			return c
+		}
+		return compareKeys(a, b)
+	})
+
+	for _, key := range keys {
+		fileNode := m.nodes[key]
+		if len(fileNode.children) > 0 {
+			edited := slices.ContainsFunc(fileNode.children, func(child *FileNode) bool {
+				childKey := m.keys[child]
+				return !removes[childKey] && !m.kept[childKey]
+			})
+			if edited {
+				m.conflict(ConflictDeleteEdit, key, ours, theirs)
+			}
+			continue
+		}
+
+		if fileNode.parent != nil {
+			fileNode.parent.children = RemoveChild(fileNode.parent, fileNode)
+		}
+		m.removed[fileNode] = true
+	}
+}
+
+// folders returns the merged Folders, rebuilding the path
+// of every folder from its root down
+func (m *merger) folders() []Folder {
+	for _, fileNode := range m.order {
+		if fileNode.parent == nil && !m.removed[fileNode] {
+			fileNode.file.Paths = AppendPath("", fileNode.file.Name)
+			ChangeChildPaths(fileNode, DefaultPathCodec)
+		}
+	}
+
+	folders := []Folder{}
+	for _, fileNode := range m.order {
+		if !m.removed[fileNode] {
+			folders = append(folders, fileNode.file)
+		}
+	}
+
+	return folders
+}
+
+func compareKeys(a folderKey, b folderKey) int {
+	if c := strings.Compare(a.orgID.String(), b.orgID.String()); c != 0 {
+		return c
+	}
+	if c := strings.Compare(a.name, b.name); c != 0 {
+		return c
+	}
+	return strings.Compare(a.id.String(), b.id.String())
+}
@@ -0,0 +1,328 @@
+package folder_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// editFolders returns a copy of 'folders' with the paths of
+// the folders named in 'paths' changed, dropping those with an
+// empty path, followed by the folders in 'added'
+func editFolders(folders []folder.Folder, paths map[string]string, added ...folder.Folder) []folder.Folder {
+	edited := []folder.Folder{}
+	for _, f := range folders {
+		if p, exists := paths[f.Name]; exists {
+			if p == "" {
+				continue
+			}
+			f.Paths = p
+		}
+		edited = append(edited, f)
+	}
+
+	return append(edited, added...)
+}
+
+func Test_folder_Merge(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	tests := [...]struct {
+		name      string
+		ours      []folder.Folder
+		theirs    []folder.Folder
+		want      []folder.Folder
+		conflicts []folder.MergeConflict
+	}{
+		{
+			name: "Changes to different folders",
+			ours: editFolders(exampleFolders, map[string]string{
+				"bravo":   "golf.bravo",
+				"charlie": "golf.bravo.charlie",
+			}),
+			theirs: editFolders(exampleFolders, map[string]string{
+				"echo": "echo",
+			}, folder.Folder{Name: "hotel", OrgId: orgID, Paths: "alpha.delta.hotel"}),
+			want: editFolders(exampleFolders, map[string]string{
+				"bravo":   "golf.bravo",
+				"charlie": "golf.bravo.charlie",
+				"echo":    "echo",
+			}, folder.Folder{Name: "hotel", OrgId: orgID, Paths: "alpha.delta.hotel"}),
+			conflicts: []folder.MergeConflict{},
+		},
+		{
+			name: "Same change on both sides",
+			ours: editFolders(exampleFolders, map[string]string{
+				"golf": "",
+			}),
+			theirs: editFolders(exampleFolders, map[string]string{
+				"golf": "",
+			}),
+			want: editFolders(exampleFolders, map[string]string{
+				"golf": "",
+			}),
+			conflicts: []folder.MergeConflict{},
+		},
+		{
+			name: "Moved to different parents",
+			ours: editFolders(exampleFolders, map[string]string{
+				"bravo":   "golf.bravo",
+				"charlie": "golf.bravo.charlie",
+			}),
+			theirs: editFolders(exampleFolders, map[string]string{
+				"bravo":   "alpha.delta.bravo",
+				"charlie": "alpha.delta.bravo.charlie",
+			}),
+			want: exampleFolders,
+			conflicts: []folder.MergeConflict{
+				{
+					Kind:   folder.ConflictMoveMove,
+					OrgId:  orgID,
+					Name:   "bravo",
+					Ours:   "moved to golf.bravo",
+					Theirs: "moved to alpha.delta.bravo",
+				},
+			},
+		},
+		{
+			name: "Moves which make a cycle",
+			ours: editFolders(exampleFolders, map[string]string{
+				"delta": "alpha.bravo.delta",
+				"echo":  "alpha.bravo.delta.echo",
+			}),
+			theirs: editFolders(exampleFolders, map[string]string{
+				"bravo":   "alpha.delta.echo.bravo",
+				"charlie": "alpha.delta.echo.bravo.charlie",
+			}),
+			want: editFolders(exampleFolders, map[string]string{
+				"delta": "alpha.bravo.delta",
+				"echo":  "alpha.bravo.delta.echo",
+			}),
+			conflicts: []folder.MergeConflict{
+				{
+					Kind:   folder.ConflictCycle,
+					OrgId:  orgID,
+					Name:   "bravo",
+					Theirs: "moved to alpha.delta.echo.bravo",
+				},
+			},
+		},
+		{
+			name: "Removed and moved",
+			ours: editFolders(exampleFolders, map[string]string{
+				"delta": "",
+				"echo":  "",
+			}),
+			theirs: editFolders(exampleFolders, map[string]string{
+				"echo": "golf.echo",
+			}),
+			want: exampleFolders,
+			conflicts: []folder.MergeConflict{
+				{
+					Kind:   folder.ConflictDeleteEdit,
+					OrgId:  orgID,
+					Name:   "echo",
+					Ours:   "removed",
+					Theirs: "moved to golf.echo",
+				},
+			},
+		},
+		{
+			name: "Removed and added into",
+			ours: editFolders(exampleFolders, map[string]string{
+				"golf": "",
+			}),
+			theirs: editFolders(exampleFolders, nil,
+				folder.Folder{Name: "hotel", OrgId: orgID, Paths: "golf.hotel"},
+			),
+			want: editFolders(exampleFolders, nil,
+				folder.Folder{Name: "hotel", OrgId: orgID, Paths: "golf.hotel"},
+			),
+			conflicts: []folder.MergeConflict{
+				{
+					Kind:  folder.ConflictDeleteEdit,
+					OrgId: orgID,
+					Name:  "golf",
+					Ours:  "removed",
+				},
+			},
+		},
+		{
+			name: "Added at different paths",
+			ours: editFolders(exampleFolders, nil,
+				folder.Folder{Name: "hotel", OrgId: orgID, Paths: "golf.hotel"},
+			),
+			theirs: editFolders(exampleFolders, nil,
+				folder.Folder{Name: "hotel", OrgId: orgID, Paths: "alpha.hotel"},
+			),
+			want: exampleFolders,
+			conflicts: []folder.MergeConflict{
+				{
+					Kind:   folder.ConflictAddAdd,
+					OrgId:  orgID,
+					Name:   "hotel",
+					Ours:   "added at golf.hotel",
+					Theirs: "added at alpha.hotel",
+				},
+			},
+		},
+		{
+			name: "Renamed and moved",
+			ours: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "brave", OrgId: orgID, Paths: "alpha.brave"},
+				{Name: "charlie", OrgId: orgID, Paths: "alpha.brave.charlie"},
+				{Name: "delta", OrgId: orgID, Paths: "alpha.delta"},
+				{Name: "echo", OrgId: orgID, Paths: "alpha.delta.echo"},
+				exampleFolders[5],
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+			},
+			theirs: editFolders(exampleFolders, map[string]string{
+				"bravo":   "golf.bravo",
+				"charlie": "golf.bravo.charlie",
+			}, folder.Folder{Name: "india", OrgId: orgID, Paths: "golf.bravo.india"}),
+			want: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "brave", OrgId: orgID, Paths: "golf.brave"},
+				{Name: "charlie", OrgId: orgID, Paths: "golf.brave.charlie"},
+				{Name: "delta", OrgId: orgID, Paths: "alpha.delta"},
+				{Name: "echo", OrgId: orgID, Paths: "alpha.delta.echo"},
+				exampleFolders[5],
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+				{Name: "india", OrgId: orgID, Paths: "golf.brave.india"},
+			},
+			conflicts: []folder.MergeConflict{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := folder.Merge(exampleFolders, tt.ours, tt.theirs)
+
+			if !reflect.DeepEqual(get.Folders, tt.want) {
+				t.Errorf("Merge() = %v, want %v", get.Folders, tt.want)
+			}
+			if !reflect.DeepEqual(get.Conflicts, tt.conflicts) {
+				t.Errorf("Merge() conflicts = %v, want %v", get.Conflicts, tt.conflicts)
+			}
+		})
+	}
+}
+
+func Test_folder_Merge_IDs(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	added := folder.Folder{
+		ID:        uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a05"),
+		Name:      "hotel",
+		OrgId:     orgID,
+		Paths:     "golf.bravo.hotel",
+		Metadata:  map[string]string{"owner": "ops"},
+		CreatedAt: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+		UpdatedAt: time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC),
+	}
+	renamed := idFolders[1]
+	renamed.Name = "charlie"
+	renamed.Paths = "alpha.charlie"
+
+	ours := append(append([]folder.Folder{}, idFolders...), added)
+	theirs := []folder.Folder{idFolders[0], renamed, idFolders[2], idFolders[3]}
+	get := folder.Merge(idFolders, ours, theirs)
+
+	want := []folder.Folder{idFolders[0], renamed, idFolders[2], idFolders[3], added}
+	if !reflect.DeepEqual(get.Folders, want) {
+		t.Errorf("Merge() = %v, want %v", get.Folders, want)
+	}
+	if !reflect.DeepEqual(get.Conflicts, []folder.MergeConflict{}) {
+		t.Errorf("Merge() conflicts = %v, want none", get.Conflicts)
+	}
+}
+
+func Test_folder_Merge_RepeatedNames(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	base := []folder.Folder{
+		{Name: "a", OrgId: orgID, Paths: "a"},
+		{Name: "b", OrgId: orgID, Paths: "b"},
+		{Name: "x", OrgId: orgID, Paths: "a.x"},
+		{Name: "x", OrgId: orgID, Paths: "b.x"},
+		{Name: "y", OrgId: orgID, Paths: "a.x.y"},
+		{Name: "y", OrgId: orgID, Paths: "b.x.y"},
+	}
+	tests := [...]struct {
+		name   string
+		ours   []folder.Folder
+		theirs []folder.Folder
+		want   []folder.Folder
+	}{
+		{
+			name:   "Unchanged",
+			ours:   base,
+			theirs: base,
+			want:   base,
+		},
+		{
+			name:   "Removed on one side",
+			ours:   []folder.Folder{base[0], base[1], base[2], base[3], base[4]},
+			theirs: base,
+			want:   []folder.Folder{base[0], base[1], base[2], base[3], base[4]},
+		},
+		{
+			name:   "Added below the second",
+			ours:   base,
+			theirs: append(append([]folder.Folder{}, base...), folder.Folder{Name: "z", OrgId: orgID, Paths: "b.x.z"}),
+			want:   append(append([]folder.Folder{}, base...), folder.Folder{Name: "z", OrgId: orgID, Paths: "b.x.z"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get := folder.Merge(base, tt.ours, tt.theirs)
+
+			if !reflect.DeepEqual(get.Folders, tt.want) {
+				t.Errorf("Merge() = %v, want %v", get.Folders, tt.want)
+			}
+			if !reflect.DeepEqual(get.Conflicts, []folder.MergeConflict{}) {
+				t.Errorf("Merge() conflicts = %v, want none", get.Conflicts)
+			}
+		})
+	}
+}
+
+func Test_folder_Merge_MoveMoveSameName(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	charlieID := uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a06")
+	charlie := folder.Folder{ID: charlieID, Name: "charlie", OrgId: orgID, Paths: "charlie"}
+	base := append(append([]folder.Folder{}, idFolders...), charlie)
+
+	ours := append([]folder.Folder{}, idFolders...)
+	ourCharlie := charlie
+	ourCharlie.Paths = "alpha.bravo.charlie"
+	ours = append(ours, ourCharlie)
+	theirs := append([]folder.Folder{}, idFolders...)
+	theirCharlie := charlie
+	theirCharlie.Paths = "golf.bravo.charlie"
+	theirs = append(theirs, theirCharlie)
+
+	get := folder.Merge(base, ours, theirs)
+	if !reflect.DeepEqual(get.Folders, base) {
+		t.Errorf("Merge() = %v, want %v", get.Folders, base)
+	}
+	conflicts := []folder.MergeConflict{
+		{
+			Kind:   folder.ConflictMoveMove,
+			OrgId:  orgID,
+			ID:     charlieID,
+			Name:   "charlie",
+			Ours:   "moved to alpha.bravo.charlie",
+			Theirs: "moved to golf.bravo.charlie",
+		},
+	}
+	if !reflect.DeepEqual(get.Conflicts, conflicts) {
+		t.Errorf("Merge() conflicts = %v, want %v", get.Conflicts, conflicts)
+	}
+}
@@ -2,6 +2,8 @@ package folder
 
 import (
 	"errors"
+	"slices"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -25,7 +27,7 @@ func FindFolder(name string, orgs map[uuid.UUID]Organization) (*FileNode, string
 // given 'dst' FileNode is a child of 'src'
 func CheckIsChild(src *FileNode, dst *FileNode) bool {
 	for _, childNode := range src.children {
-		if childNode.file.Name == dst.file.Name {
+		if childNode == dst {
 			return true
 		} else if CheckIsChild(childNode, dst) {
 			return true
@@ -36,24 +38,30 @@ func CheckIsChild(src *FileNode, dst *FileNode) bool {
 }
 
 // RemoveChild returns the children of 'parentNode'
-// after removing the FileNode containing the
-// Folder with name 'fileToRemove'
-func RemoveChild(parentNode *FileNode, fileToRemove string) []*FileNode {
-	for i, fileNode := range parentNode.children {
-		if fileNode.file.Name == fileToRemove {
-			return append(parentNode.children[:i], parentNode.children[:i+1]...)
-		}
-	}
-
-	return parentNode.children
+// after removing 'childNode', leaving any other child
+// with the same name
+func RemoveChild(parentNode *FileNode, childNode *FileNode) []*FileNode {
+	return slices.DeleteFunc(parentNode.children, func(c *FileNode) bool {
+		return c == childNode
+	})
 }
 
 // ChangeChildPaths changes all paths of the Folders
-// contained within child FileNodes of 'parentNode'
-func ChangeChildPaths(parentNode *FileNode) {
+// contained within child FileNodes of 'parentNode',
+// written using 'codec'
+func ChangeChildPaths(parentNode *FileNode, codec PathCodec) {
 	for _, childNode := range parentNode.children {
-		childNode.file.Paths = parentNode.file.Paths + "." + childNode.file.Name
-		ChangeChildPaths(childNode)
+		childNode.file.Paths = codec.Append(parentNode.file.Paths, childNode.file.Name)
+		ChangeChildPaths(childNode, codec)
+	}
+}
+
+// StampUpdated sets the UpdatedAt time of the Folders
+// contained within 'fileNode' and every FileNode below it
+func StampUpdated(fileNode *FileNode, now time.Time) {
+	fileNode.file.UpdatedAt = now
+	for _, childNode := range fileNode.children {
+		StampUpdated(childNode, now)
 	}
 }
 
@@ -63,52 +71,150 @@ func CreateFolderSlice(orgs map[uuid.UUID]Organization) []Folder {
 	folders := []Folder{}
 	for _, org := range orgs {
 		for _, fileNode := range org.folders {
-			folders = append(folders, fileNode.file)
+			folders = append(folders, fileNode.file.clone())
 		}
 	}
 
 	return folders
 }
 
-// MoveFolder moves a folder with 'name' and all its children
-// to a different parent folder
-func (f *driver) MoveFolder(name string, dst string) ([]Folder, error) {
-	if name == dst {
-		return []Folder{}, errors.New("error: cannot move a folder to itself")
+// checkMove returns the error for moving 'srcFolder', looked
+// up using 'name', to 'dstFolder', looked up using 'dst', or nil
+// if the move is allowed. A nil FileNode is one that was not
+// found in the organization with 'orgID', or in any organization
+// if 'orgID' is uuid.Nil.
+func (f *driver) checkMove(srcFolder *FileNode, dstFolder *FileNode, name string, dst string, orgID uuid.UUID) error {
+	if name == dst || (srcFolder != nil && srcFolder == dstFolder) {
+		return errors.New("error: cannot move a folder to itself")
+	}
+	// Suggestions for a folder which does not exist come from
+	// the organization of the other folder, when the move does
+	// not name one, so other organizations are never suggested
+	if orgID == uuid.Nil && srcFolder != nil {
+		orgID = srcFolder.file.OrgId
+	} else if orgID == uuid.Nil && dstFolder != nil {
+		orgID = dstFolder.file.OrgId
 	}
-
-	srcFolder, srcID := FindFolder(name, f.orgs)
-	dstFolder, dstID := FindFolder(dst, f.orgs)
 	if srcFolder == nil {
-		return []Folder{}, errors.New("error: source folder does not exist")
+		return f.addSuggestions(&NotFoundError{Kind: KindSourceFolder, OrgID: orgID, Ref: name})
 	}
 	if dstFolder == nil {
-		return []Folder{}, errors.New("error: destination folder does not exist")
+		return f.addSuggestions(&NotFoundError{Kind: KindDestinationFolder, OrgID: orgID, Ref: dst})
 	}
-	if srcID != dstID {
-		return []Folder{}, errors.New("error: cannot move a folder to a different organization")
+	if srcFolder.file.OrgId != dstFolder.file.OrgId {
+		return errors.New("error: cannot move a folder to a different organization")
 	}
 	if CheckIsChild(srcFolder, dstFolder) {
-		return []Folder{}, errors.New("error: cannot move a folder to a child of itself")
+		return errors.New("error: cannot move a folder to a child of itself")
+	}
+	err := f.checkSiblings(srcFolder.file.OrgId, dstFolder.children, srcFolder.file.Name, srcFolder)
+	if err != nil {
+		return err
 	}
+	org := f.orgs[srcFolder.file.OrgId]
+	paths := f.codec.Append(dstFolder.file.Paths, srcFolder.file.Name)
+	if existing := org.index.Lookup(paths); existing != nil && existing != srcFolder {
+		return &NameConflictError{OrgID: srcFolder.file.OrgId, Name: srcFolder.file.Name, Existing: existing.file.Paths}
+	}
+
+	return f.checkMoveLimits(srcFolder, dstFolder)
+}
+
+// MoveFolder moves a folder with 'name', path or ID and all its children
+// to a different parent folder, unless a folder there has the same
+// name under the SiblingPolicy of the organization, or already has
+// the path it would move to, which are both a NameConflictError
+func (f *driver) MoveFolder(name string, dst string) ([]Folder, error) {
+	srcFolder, dstFolder := f.findMoveNodes(uuid.Nil, name, dst)
+	if err := f.checkMove(srcFolder, dstFolder, name, dst, uuid.Nil); err != nil {
+		return []Folder{}, err
+	}
+	f.moveNode(srcFolder, dstFolder)
+
+	folderList := CreateFolderSlice(f.orgs)
+	return folderList, nil
+}
 
+// moveNode moves 'srcFolder' below 'dstFolder', once
+// the move has been checked
+func (f *driver) moveNode(srcFolder *FileNode, dstFolder *FileNode) {
 	// Change parent of source file to new parent, and remove source file
 	// from the children of old parent node
 	srcParent := srcFolder.parent
 	if srcParent != nil {
-		srcParent.children = RemoveChild(srcParent, srcFolder.file.Name)
+		srcParent.children = RemoveChild(srcParent, srcFolder)
+	}
+	if f.incrementalStats {
+		AddDescendants(srcParent, -(srcFolder.descendants + 1))
 	}
 
 	// Set parent of source file to new parent, add source file to destination
 	// node children.
 	srcFolder.parent = dstFolder
 	dstFolder.children = append(dstFolder.children, srcFolder)
+	if f.incrementalStats {
+		AddDescendants(dstFolder, srcFolder.descendants+1)
+	}
 
 	// Change file paths according to new parent file path for all children
-	// in the subtree that has been moved
-	srcFolder.file.Paths = srcFolder.parent.file.Paths + "." + srcFolder.file.Name
-	ChangeChildPaths(srcFolder)
+	// in the subtree that has been moved, keeping the index of paths in step
+	index := f.orgs[srcFolder.file.OrgId].index
+	if index != nil {
+		index.RemoveSubtree(srcFolder)
+	}
+	srcFolder.file.Paths = f.codec.Append(srcFolder.parent.file.Paths, srcFolder.file.Name)
+	ChangeChildPaths(srcFolder, f.codec)
+	StampUpdated(srcFolder, f.now())
+	if index != nil {
+		index.AddSubtree(srcFolder)
+	}
+}
 
-	folderList := CreateFolderSlice(f.orgs)
-	return folderList, nil
+// MoveChange is a folder whose path would be changed by a move
+type MoveChange struct {
+	Name     string    `json:"name"`
+	OrgId    uuid.UUID `json:"org_id"`
+	OldPaths string    `json:"old_paths"`
+	NewPaths string    `json:"new_paths"`
+}
+
+// PlanMove returns the changes to the paths of 'srcFolder'
+// and every folder below it if it was moved to 'dstFolder',
+// in pre-order, without changing any FileNodes. New paths
+// are written using 'codec'.
+func PlanMove(srcFolder *FileNode, dstFolder *FileNode, codec PathCodec) []MoveChange {
+	changes := []MoveChange{}
+
+	var plan func(fileNode *FileNode, parentPaths string)
+	plan = func(fileNode *FileNode, parentPaths string) {
+		newPaths := codec.Append(parentPaths, fileNode.file.Name)
+		changes = append(changes, MoveChange{
+			Name:     fileNode.file.Name,
+			OrgId:    fileNode.file.OrgId,
+			OldPaths: fileNode.file.Paths,
+			NewPaths: newPaths,
+		})
+		for _, childNode := range fileNode.children {
+			plan(childNode, newPaths)
+		}
+	}
+	plan(srcFolder, dstFolder.file.Paths)
+
+	return changes
+}
+
+// DryRunMove returns the changes MoveFolder would make to move
+// 'src' to 'dst' in the organization, which are each a path or
+// a name, checking the move in the same way without making it
+func (f *driver) DryRunMove(orgID uuid.UUID, src string, dst string) ([]MoveChange, error) {
+	if _, err := f.findOrg(orgID); err != nil {
+		return []MoveChange{}, err
+	}
+
+	srcFolder, dstFolder := f.findMoveNodes(orgID, src, dst)
+	if err := f.checkMove(srcFolder, dstFolder, src, dst, orgID); err != nil {
+		return []MoveChange{}, err
+	}
+
+	return PlanMove(srcFolder, dstFolder, f.codec), nil
 }
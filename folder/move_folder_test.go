@@ -4,11 +4,15 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/georgechieng-sc/interns-2022/folder"
 	"github.com/gofrs/uuid"
 )
 
+// movedAt is the time folders moved by MoveFolder are stamped with
+var movedAt = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
 func Test_folder_MoveFolder(t *testing.T) {
 	t.Parallel()
 	tests := [...]struct {
@@ -36,6 +40,64 @@ func Test_folder_MoveFolder(t *testing.T) {
 			want: []folder.Folder{},
 			err:  errors.New("error: source folder does not exist"),
 		},
+		{
+			name:  "Misspelled Source Folder",
+			src:   "alpah",
+			dst:   "beta",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			folders: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "beta",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "beta",
+				},
+			},
+
+			want: []folder.Folder{},
+			err:  errors.New("error: source folder does not exist, did you mean \"alpha\"?"),
+		},
+		{
+			name:  "Misspelled Source Folder with no Destination",
+			src:   "alpah",
+			dst:   "beta",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			folders: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+			},
+
+			want: []folder.Folder{},
+			err:  errors.New("error: source folder does not exist"),
+		},
+		{
+			name:  "Misspelled Destination Folder in a different org",
+			src:   "alpha",
+			dst:   "betta",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			folders: []folder.Folder{
+				{
+					Name:  "alpha",
+					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths: "alpha",
+				},
+				{
+					Name:  "beta",
+					OrgId: uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7"),
+					Paths: "beta",
+				},
+			},
+
+			want: []folder.Folder{},
+			err:  errors.New("error: destination folder does not exist"),
+		},
 		{
 			name:  "Invalid Destination Folder",
 			src:   "alpha",
@@ -161,9 +223,10 @@ func Test_folder_MoveFolder(t *testing.T) {
 
 			want: []folder.Folder{
 				{
-					Name:  "alpha",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "beta.alpha",
+					Name:      "alpha",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "beta.alpha",
+					UpdatedAt: movedAt,
 				},
 				{
 					Name:  "beta",
@@ -171,9 +234,10 @@ func Test_folder_MoveFolder(t *testing.T) {
 					Paths: "beta",
 				},
 				{
-					Name:  "charlie",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "beta.alpha.charlie",
+					Name:      "charlie",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "beta.alpha.charlie",
+					UpdatedAt: movedAt,
 				},
 			},
 			err: nil,
@@ -223,14 +287,16 @@ func Test_folder_MoveFolder(t *testing.T) {
 					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
-					Name:  "bravo",
-					Paths: "golf.bravo",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Name:      "bravo",
+					Paths:     "golf.bravo",
+					UpdatedAt: movedAt,
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
-					Name:  "charlie",
-					Paths: "golf.bravo.charlie",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Name:      "charlie",
+					Paths:     "golf.bravo.charlie",
+					UpdatedAt: movedAt,
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
 					Name:  "delta",
@@ -295,14 +361,16 @@ func Test_folder_MoveFolder(t *testing.T) {
 					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
-					Name:  "bravo",
-					Paths: "alpha.delta.bravo",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Name:      "bravo",
+					Paths:     "alpha.delta.bravo",
+					UpdatedAt: movedAt,
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
-					Name:  "charlie",
-					Paths: "alpha.delta.bravo.charlie",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+					Name:      "charlie",
+					Paths:     "alpha.delta.bravo.charlie",
+					UpdatedAt: movedAt,
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
 				},
 				{
 					Name:  "delta",
@@ -371,24 +439,28 @@ func Test_folder_MoveFolder(t *testing.T) {
 					Paths: "alpha.bravo",
 				},
 				{
-					Name:  "charlie",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "alpha.charlie",
+					Name:      "charlie",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "alpha.charlie",
+					UpdatedAt: movedAt,
 				},
 				{
-					Name:  "delta",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "alpha.charlie.delta",
+					Name:      "delta",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "alpha.charlie.delta",
+					UpdatedAt: movedAt,
 				},
 				{
-					Name:  "gamma",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "alpha.charlie.gamma",
+					Name:      "gamma",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "alpha.charlie.gamma",
+					UpdatedAt: movedAt,
 				},
 				{
-					Name:  "epsilon",
-					OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
-					Paths: "alpha.charlie.delta.epsilon",
+					Name:      "epsilon",
+					OrgId:     uuid.FromStringOrNil(folder.DefaultOrgID),
+					Paths:     "alpha.charlie.delta.epsilon",
+					UpdatedAt: movedAt,
 				},
 			},
 			err: nil,
@@ -397,7 +469,7 @@ func Test_folder_MoveFolder(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			f := folder.NewDriver(tt.folders)
+			f := folder.NewDriver(tt.folders, folder.WithClock(func() time.Time { return movedAt }))
 			get, err := f.MoveFolder(tt.src, tt.dst)
 
 			if !reflect.DeepEqual(get, tt.want) {
@@ -415,3 +487,255 @@ func Test_folder_MoveFolder(t *testing.T) {
 	}
 
 }
+
+func Test_folder_MoveFolder_KeepsSiblings(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...))
+
+	// bravo is the first child of alpha, and delta stays
+	// behind once bravo is moved out
+	if _, err := f.MoveFolder("bravo", "golf"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+	for paths, want := range map[string][]string{
+		"alpha": {"alpha.delta"},
+		"golf":  {"golf.bravo"},
+	} {
+		children, err := f.GetImmediateChildren(orgID, paths)
+		if err != nil {
+			t.Fatalf("GetImmediateChildren() = %v, want nil for error", err)
+		}
+		get := []string{}
+		for _, child := range children {
+			get = append(get, child.Paths)
+		}
+		if !reflect.DeepEqual(get, want) {
+			t.Errorf("GetImmediateChildren(%s) = %v, want %v", paths, get, want)
+		}
+	}
+	if violations := f.CheckInvariants(orgID); len(violations) > 0 {
+		t.Errorf("CheckInvariants() = %v, want none", violations)
+	}
+}
+
+func Test_folder_MoveFolder_ByID(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(append([]folder.Folder{}, idFolders...))
+
+	// Only the bravo below golf is moved into the bravo below
+	// alpha, as both are named by their ID
+	src := idFolders[3].ID.String()
+	if _, err := f.MoveFolder(src, idFolders[1].ID.String()); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+
+	get, err := f.GetAncestors(orgID, src)
+	if err != nil {
+		t.Fatalf("GetAncestors() = %v, want nil for error", err)
+	}
+	want := []folder.Folder{idFolders[0], idFolders[1]}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAncestors() = %v, want %v", get, want)
+	}
+
+	moved, err := f.GetParent(orgID, "alpha.bravo.bravo")
+	if err != nil || moved.ID != idFolders[1].ID {
+		t.Errorf("GetParent() = %v, %v, want the folder with ID %s", moved, err, idFolders[1].ID)
+	}
+	children := f.GetAllChildFolders(orgID, idFolders[1].ID.String())
+	if len(children) != 1 || children[0].ID != idFolders[3].ID || children[0].Paths != "alpha.bravo.bravo" {
+		t.Errorf("GetAllChildFolders() = %v, want the moved folder with its ID", children)
+	}
+}
+
+func Test_folder_MoveFolder_SameNames(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	first := uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a05")
+	second := uuid.FromStringOrNil("0b6f3a4e-1c2d-4e5f-8a9b-0c1d2e3f4a06")
+	f := folder.NewDriver(append([]folder.Folder{
+		{ID: first, Name: "x", OrgId: orgID, Paths: "alpha.x"},
+		{ID: second, Name: "x", OrgId: orgID, Paths: "alpha.x"},
+	}, idFolders...))
+
+	// Only the second x is unlinked from alpha, though
+	// both have the same name
+	if _, err := f.MoveFolder(second.String(), "golf"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+	children, err := f.GetImmediateChildren(orgID, "alpha")
+	if err != nil {
+		t.Fatalf("GetImmediateChildren() = %v, want nil for error", err)
+	}
+	ids := []uuid.UUID{}
+	for _, child := range children {
+		ids = append(ids, child.ID)
+	}
+	if want := []uuid.UUID{first, idFolders[1].ID}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("GetImmediateChildren() = %v, want %v", ids, want)
+	}
+	if violations := f.CheckInvariants(orgID); len(violations) > 0 {
+		t.Errorf("CheckInvariants() = %v, want none", violations)
+	}
+
+	// alpha has a child named bravo, but the bravo below
+	// golf is not one of its children
+	if _, err := f.MoveFolder(idFolders[0].ID.String(), idFolders[3].ID.String()); err != nil {
+		t.Errorf("MoveFolder() = %v, want nil for error", err)
+	}
+}
+
+func Test_folder_MoveFolder_ByPath(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...))
+
+	// A move planned by DryRunMove is made by MoveFolder,
+	// as both find folders by their path
+	changes, err := f.DryRunMove(orgID, "alpha.bravo", "golf")
+	if err != nil {
+		t.Fatalf("DryRunMove() = %v, want nil for error", err)
+	}
+	if _, err := f.MoveFolder("alpha.bravo", "golf"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+	for _, c := range changes {
+		if _, err := f.GetParent(orgID, c.NewPaths); err != nil {
+			t.Errorf("GetParent(%s) = %v, want the folder moved there", c.NewPaths, err)
+		}
+	}
+}
+
+func Test_folder_MoveFolder_ExistingPath(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(append([]folder.Folder{}, idFolders...))
+
+	// Without a sibling policy the bravo below golf still
+	// cannot be moved to the path of the bravo below alpha
+	want := errors.New(`error: folder name "bravo" conflicts with alpha.bravo`)
+	src := idFolders[3].ID.String()
+	if _, err := f.DryRunMove(orgID, src, "alpha"); err == nil || err.Error() != want.Error() {
+		t.Errorf("DryRunMove() = %v, want %v for error", err, want)
+	}
+	if _, err := f.MoveFolder(src, "alpha"); err == nil || err.Error() != want.Error() {
+		t.Errorf("MoveFolder() = %v, want %v for error", err, want)
+	} else if !errors.Is(err, folder.ErrNameConflict) {
+		t.Errorf("MoveFolder() = %v, want a NameConflictError", err)
+	}
+	if violations := f.CheckInvariants(orgID); len(violations) > 0 {
+		t.Errorf("CheckInvariants() = %v, want none", violations)
+	}
+}
+
+func Test_folder_DryRunMove(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		src   string
+		dst   string
+		want  []folder.MoveChange
+		err   error
+	}{
+		{
+			name:  "Move subtree to a sibling",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "bravo",
+			dst:   "alpha.delta",
+			want: []folder.MoveChange{
+				{
+					Name:     "bravo",
+					OrgId:    uuid.FromStringOrNil(folder.DefaultOrgID),
+					OldPaths: "alpha.bravo",
+					NewPaths: "alpha.delta.bravo",
+				},
+				{
+					Name:     "charlie",
+					OrgId:    uuid.FromStringOrNil(folder.DefaultOrgID),
+					OldPaths: "alpha.bravo.charlie",
+					NewPaths: "alpha.delta.bravo.charlie",
+				},
+			},
+		},
+		{
+			name:  "Move root folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "golf",
+			dst:   "echo",
+			want: []folder.MoveChange{
+				{
+					Name:     "golf",
+					OrgId:    uuid.FromStringOrNil(folder.DefaultOrgID),
+					OldPaths: "golf",
+					NewPaths: "alpha.delta.echo.golf",
+				},
+			},
+		},
+		{
+			name:  "Moving to same folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "alpha.delta",
+			dst:   "delta",
+			want:  []folder.MoveChange{},
+			err:   errors.New("error: cannot move a folder to itself"),
+		},
+		{
+			name:  "Destination is a child of Source",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "alpha",
+			dst:   "charlie",
+			want:  []folder.MoveChange{},
+			err:   errors.New("error: cannot move a folder to a child of itself"),
+		},
+		{
+			name:  "Destination in a different org",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "alpha",
+			dst:   "foxtrot",
+			want:  []folder.MoveChange{},
+			err:   errors.New("error: cannot move a folder to a different organization"),
+		},
+		{
+			name:  "Invalid Source Folder",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			src:   "hotel",
+			dst:   "alpha",
+			want:  []folder.MoveChange{},
+			err:   errors.New("error: source folder does not exist"),
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			src:   "bravo",
+			dst:   "alpha",
+			want:  []folder.MoveChange{},
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			before := f.GetFoldersByOrgID(tt.orgID)
+			get, err := f.DryRunMove(tt.orgID, tt.src, tt.dst)
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("DryRunMove() = %v, want %v for output", get, tt.want)
+			}
+			if after := f.GetFoldersByOrgID(tt.orgID); !reflect.DeepEqual(after, before) {
+				t.Errorf("DryRunMove() changed the folders to %v", after)
+			}
+
+			if tt.err != nil && err == nil {
+				t.Errorf("DryRunMove() = nil, want %v for error", tt.err)
+			} else if tt.err == nil && err != nil {
+				t.Errorf("DryRunMove() = %v, want nil for error", err)
+			} else if tt.err != nil && err != nil && tt.err.Error() != err.Error() {
+				t.Errorf("DryRunMove() = %v\n want %v for error", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package folder
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// OrphanPolicy is what a driver does with a loaded folder
+// whose parent, named in its path, is not one of the folders
+type OrphanPolicy int
+
+const (
+	// OrphanKeep leaves the folder without a parent, so it
+	// is found as a root folder with the path it was given
+	OrphanKeep OrphanPolicy = iota
+	// OrphanReject stops the driver loading, returning
+	// an OrphanError
+	OrphanReject
+	// OrphanLostFound moves the folder below a root folder
+	// named LostFoundName, created if there is none. A folder
+	// whose name is taken there is renamed as SuffixName does.
+	OrphanLostFound
+	// OrphanCreateAncestors creates the folders missing from
+	// its path, so it keeps the path it was given
+	OrphanCreateAncestors
+)
+
+// LostFoundName is the name of the root folder orphans
+// are moved below by OrphanLostFound. It is allowed by
+// the DefaultNameValidator, unlike "lost+found".
+const LostFoundName = "lost_found"
+
+// WithOrphanPolicy makes the driver handle the folders it
+// loads whose parents are missing using 'policy', in place
+// of OrphanKeep
+func WithOrphanPolicy(policy OrphanPolicy) DriverOption {
+	return func(d *driver) {
+		d.orphanPolicy = policy
+	}
+}
+
+// Orphan is a loaded folder whose parent was missing
+type Orphan struct {
+	// Folder is the folder once the OrphanPolicy was applied
+	Folder Folder `json:"folder"`
+	// LoadedPaths is the path the folder was loaded with
+	LoadedPaths string `json:"loaded_paths"`
+	// Parent is the path of the missing parent
+	Parent string `json:"parent"`
+	// Created are the folders created for the orphan, which
+	// are its missing ancestors or the lost and found folder
+	Created []Folder `json:"created,omitempty"`
+}
+
+// LoadReport describes the folders a driver had to
+// change when it loaded them
+type LoadReport struct {
+	Policy OrphanPolicy `json:"policy"`
+	// Orphans are sorted by organization, then in the
+	// order they were loaded
+	Orphans []Orphan `json:"orphans"`
+}
+
+// LoadReport returns the orphans found when the driver
+// loaded its folders, and what was done with them
+func (f *driver) LoadReport() LoadReport {
+	orphans := []Orphan{}
+	for _, orphan := range f.orphans {
+		orphan.Folder = orphan.Folder.clone()
+		orphans = append(orphans, orphan)
+	}
+
+	return LoadReport{
+		Policy:  f.orphanPolicy,
+		Orphans: orphans,
+	}
+}
+
+// OrphanError is returned by a driver using OrphanReject
+// for the first folder it loads whose parent is missing
+type OrphanError struct {
+	OrgID uuid.UUID
+	// Paths is the path of the folder, and Parent the
+	// path of its missing parent
+	Paths  string
+	Parent string
+}
+
+func (e *OrphanError) Error() string {
+	return fmt.Sprintf("error: parent %s of folder %s does not exist", e.Parent, e.Paths)
+}
+
+func (e *OrphanError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// FindOrphans returns the FileNodes in 'folders' without a
+// parent whose path, split using 'codec', names a parent
+func FindOrphans(folders []*FileNode, codec PathCodec) []*FileNode {
+	orphans := []*FileNode{}
+	for _, fileNode := range folders {
+		if fileNode.parent == nil && len(codec.Split(fileNode.file.Paths)) > 1 {
+			orphans = append(orphans, fileNode)
+		}
+	}
+
+	return orphans
+}
+
+// adoptOrphans applies the OrphanPolicy of the driver to the
+// orphans in 'orgs', which have been linked, recording each
+// of them for LoadReport
+func (f *driver) adoptOrphans(orgs map[uuid.UUID]Organization) error {
+	orgIDs := make([]uuid.UUID, 0, len(orgs))
+	for orgID := range orgs {
+		orgIDs = append(orgIDs, orgID)
+	}
+	slices.SortFunc(orgIDs, func(a uuid.UUID, b uuid.UUID) int {
+		return strings.Compare(a.String(), b.String())
+	})
+
+	for _, orgID := range orgIDs {
+		org := orgs[orgID]
+		for _, fileNode := range FindOrphans(org.folders, f.codec) {
+			sections := f.codec.Split(fileNode.file.Paths)
+			orphan := Orphan{
+				LoadedPaths: fileNode.file.Paths,
+				Parent:      f.codec.Join(sections[:len(sections)-1]),
+			}
+
+			switch f.orphanPolicy {
+			case OrphanReject:
+				return &OrphanError{OrgID: orgID, Paths: orphan.LoadedPaths, Parent: orphan.Parent}
+			case OrphanLostFound:
+				lostFound, created := f.addFolder(orgID, &org, nil, LostFoundName)
+				if created {
+					orphan.Created = append(orphan.Created, lostFound.file.clone())
+				}
+				org.index.RemoveSubtree(fileNode)
+				taken := func(name string) bool {
+					return org.index.Lookup(f.codec.Append(lostFound.file.Paths, name)) != nil
+				}
+				if taken(fileNode.file.Name) {
+					fileNode.file.Name = SuffixName(fileNode.file.Name, taken)
+				}
+				fileNode.parent = lostFound
+				lostFound.children = append(lostFound.children, fileNode)
+				fileNode.file.Paths = f.codec.Append(lostFound.file.Paths, fileNode.file.Name)
+				ChangeChildPaths(fileNode, f.codec)
+				org.index.AddSubtree(fileNode)
+			case OrphanCreateAncestors:
+				var parentNode *FileNode
+				for _, name := range sections[:len(sections)-1] {
+					var created bool
+					parentNode, created = f.addFolder(orgID, &org, parentNode, name)
+					if created {
+						orphan.Created = append(orphan.Created, parentNode.file.clone())
+					}
+				}
+				fileNode.parent = parentNode
+				parentNode.children = append(parentNode.children, fileNode)
+			}
+
+			orphan.Folder = fileNode.file.clone()
+			f.orphans = append(f.orphans, orphan)
+		}
+		orgs[orgID] = org
+	}
+
+	return nil
+}
+
+// addFolder returns the FileNode with 'name' below 'parentNode'
+// in 'org', the organization with 'orgID', or the root folder
+// with 'name' if 'parentNode' is nil, adding and indexing a new
+// one if there is none, and whether it was added
+func (f *driver) addFolder(orgID uuid.UUID, org *Organization, parentNode *FileNode, name string) (*FileNode, bool) {
+	paths := f.codec.Append("", name)
+	if parentNode != nil {
+		paths = f.codec.Append(parentNode.file.Paths, name)
+	}
+	if fileNode := org.index.Lookup(paths); fileNode != nil {
+		return fileNode, false
+	}
+
+	now := f.now()
+	fileNode := NewFileNode(Folder{
+		ID:        uuid.Must(uuid.NewV4()),
+		Name:      name,
+		OrgId:     orgID,
+		Paths:     paths,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+	if parentNode != nil {
+		fileNode.parent = parentNode
+		parentNode.children = append(parentNode.children, fileNode)
+	}
+	org.folders = append(org.folders, fileNode)
+	org.index.Add(fileNode)
+
+	return fileNode, true
+}
@@ -0,0 +1,146 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// orphanFolders has "zulu", whose parents "x" and "x.y"
+// are missing, with a child of its own
+var orphanFolders = []folder.Folder{
+	{Name: "alpha", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha"},
+	{Name: "bravo", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "alpha.bravo"},
+	{Name: "zulu", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "x.y.zulu"},
+	{Name: "kilo", OrgId: uuid.FromStringOrNil(folder.DefaultOrgID), Paths: "x.y.zulu.kilo"},
+}
+
+func Test_folder_OrphanPolicy(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	tests := [...]struct {
+		name      string
+		policy    folder.OrphanPolicy
+		zulu      string
+		kilo      string
+		ancestors []string
+		created   []string
+		err       error
+	}{
+		{
+			name:      "Keep as a root folder",
+			policy:    folder.OrphanKeep,
+			zulu:      "x.y.zulu",
+			kilo:      "x.y.zulu.kilo",
+			ancestors: []string{},
+			created:   []string{},
+		},
+		{
+			name:   "Reject",
+			policy: folder.OrphanReject,
+			err:    errors.New("error: parent x.y of folder x.y.zulu does not exist"),
+		},
+		{
+			name:      "Attach to lost and found",
+			policy:    folder.OrphanLostFound,
+			zulu:      "lost_found.zulu",
+			kilo:      "lost_found.zulu.kilo",
+			ancestors: []string{"lost_found"},
+			created:   []string{"lost_found"},
+		},
+		{
+			name:      "Create missing ancestors",
+			policy:    folder.OrphanCreateAncestors,
+			zulu:      "x.y.zulu",
+			kilo:      "x.y.zulu.kilo",
+			ancestors: []string{"x", "x.y"},
+			created:   []string{"x", "x.y"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := folder.LoadDriver(orphanFolders, folder.WithOrphanPolicy(tt.policy))
+
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("LoadDriver() = %v, want %v for error", err, tt.err)
+				} else if !errors.Is(err, folder.ErrNotFound) {
+					t.Errorf("LoadDriver() = %v, want an OrphanError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadDriver() = %v, want nil for error", err)
+			}
+
+			kilo := f.GetAllChildFolders(orgID, "zulu")
+			if len(kilo) != 1 || kilo[0].Paths != tt.kilo {
+				t.Errorf("GetAllChildFolders() = %v, want kilo with path %s", kilo, tt.kilo)
+			}
+			zulu, err := f.GetAncestors(orgID, "zulu")
+			if err != nil {
+				t.Fatalf("GetAncestors() = %v, want nil for error", err)
+			}
+			ancestors := []string{}
+			for _, ancestor := range zulu {
+				ancestors = append(ancestors, ancestor.Paths)
+			}
+			if !reflect.DeepEqual(ancestors, tt.ancestors) {
+				t.Errorf("GetAncestors() = %v, want %v", ancestors, tt.ancestors)
+			}
+
+			report := f.LoadReport()
+			if len(report.Orphans) != 1 {
+				t.Fatalf("LoadReport() = %v, want one orphan", report)
+			}
+			orphan := report.Orphans[0]
+			created := []string{}
+			for _, c := range orphan.Created {
+				created = append(created, c.Paths)
+			}
+			if orphan.Folder.Paths != tt.zulu || orphan.LoadedPaths != "x.y.zulu" || orphan.Parent != "x.y" {
+				t.Errorf("LoadReport() = %v, want zulu with path %s", orphan, tt.zulu)
+			}
+			if !reflect.DeepEqual(created, tt.created) {
+				t.Errorf("LoadReport() created %v, want %v", created, tt.created)
+			}
+		})
+	}
+}
+
+func Test_folder_OrphanLostFound_SameName(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f, err := folder.LoadDriver([]folder.Folder{
+		{Name: "x", OrgId: orgID, Paths: "a.x"},
+		{Name: "x", OrgId: orgID, Paths: "b.x"},
+		{Name: "y", OrgId: orgID, Paths: "b.x.y"},
+	}, folder.WithOrphanPolicy(folder.OrphanLostFound))
+	if err != nil {
+		t.Fatalf("LoadDriver() = %v, want nil for error", err)
+	}
+
+	if violations := f.CheckInvariants(orgID); len(violations) > 0 {
+		t.Errorf("CheckInvariants() = %v, want none", violations)
+	}
+	get := []string{}
+	for _, orphan := range f.LoadReport().Orphans {
+		get = append(get, orphan.LoadedPaths+" -> "+orphan.Folder.Paths)
+	}
+	want := []string{"a.x -> lost_found.x", "b.x -> lost_found.x-2"}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("LoadReport() = %v, want %v", get, want)
+	}
+
+	children, err := f.GetImmediateChildren(orgID, "lost_found.x-2")
+	if err != nil {
+		t.Fatalf("GetImmediateChildren() = %v, want nil for error", err)
+	}
+	if len(children) != 1 || children[0].Paths != "lost_found.x-2.y" {
+		t.Errorf("GetImmediateChildren() = %v, want y with path lost_found.x-2.y", children)
+	}
+}
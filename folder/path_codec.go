@@ -0,0 +1,153 @@
+package folder
+
+import (
+	"strings"
+)
+
+// PathCodec joins the names of a folder and the folders
+// above it into a path, and splits a path back into names
+type PathCodec struct {
+	// Separator goes between the names in a path
+	Separator rune
+	// Escape, if not 0, goes before every Separator and
+	// Escape in a name, so that names may contain them
+	Escape rune
+	// Prefix goes before the name of the root folder, such
+	// as the "/" of a filesystem path
+	Prefix string
+}
+
+// DefaultPathCodec is the PathCodec of the Paths of every
+// Folder. The path of names without '.' or '\' is the names
+// joined by '.', as it has always been.
+var DefaultPathCodec = PathCodec{Separator: '.', Escape: '\\'}
+
+// SlashPathCodec writes paths as a filesystem does, such
+// as "/alpha/bravo" for the path "alpha.bravo"
+var SlashPathCodec = PathCodec{Separator: '/', Escape: '\\', Prefix: "/"}
+
+// EscapeName returns 'name' as it appears in a path
+func (c PathCodec) EscapeName(name string) string {
+	if c.Escape == 0 {
+		return name
+	}
+
+	sb := strings.Builder{}
+	for _, r := range name {
+		if r == c.Separator || r == c.Escape {
+			sb.WriteRune(c.Escape)
+		}
+		sb.WriteRune(r)
+	}
+
+	return sb.String()
+}
+
+// Join returns the path of 'names', ordered from
+// the root folder down
+func (c PathCodec) Join(names []string) string {
+	return c.Prefix + c.joinNames(names)
+}
+
+// joinNames returns 'names' joined without the Prefix,
+// for a path relative to another folder
+func (c PathCodec) joinNames(names []string) string {
+	escaped := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped = append(escaped, c.EscapeName(name))
+	}
+
+	return strings.Join(escaped, string(c.Separator))
+}
+
+// Append returns the path of a folder with 'name' below
+// the folder with 'paths', or of a root folder if 'paths'
+// is empty
+func (c PathCodec) Append(paths string, name string) string {
+	if paths == "" {
+		return c.Prefix + c.EscapeName(name)
+	}
+
+	return paths + string(c.Separator) + c.EscapeName(name)
+}
+
+// Split returns the names in 'paths', ordered from the
+// root folder down. A Prefix at the start of 'paths' is
+// dropped, and an Escape at the end is kept as it is.
+func (c PathCodec) Split(paths string) []string {
+	paths = strings.TrimPrefix(paths, c.Prefix)
+	if c.Escape == 0 {
+		return strings.Split(paths, string(c.Separator))
+	}
+
+	names := []string{}
+	sb := strings.Builder{}
+	escaped := false
+	for _, r := range paths {
+		switch {
+		case escaped:
+			sb.WriteRune(r)
+			escaped = false
+		case r == c.Escape:
+			escaped = true
+		case r == c.Separator:
+			names = append(names, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	if escaped {
+		sb.WriteRune(c.Escape)
+	}
+
+	return append(names, sb.String())
+}
+
+// SplitPath returns the names in 'paths' using the
+// DefaultPathCodec
+func SplitPath(paths string) []string {
+	return DefaultPathCodec.Split(paths)
+}
+
+// JoinPath returns the path of 'names' using the
+// DefaultPathCodec
+func JoinPath(names []string) string {
+	return DefaultPathCodec.Join(names)
+}
+
+// AppendPath returns the path of a folder with 'name'
+// below the folder with 'paths' using the DefaultPathCodec
+func AppendPath(paths string, name string) string {
+	return DefaultPathCodec.Append(paths, name)
+}
+
+// ConvertPath returns 'paths', written by the PathCodec
+// 'from', as it is written by the PathCodec 'to'
+func ConvertPath(paths string, from PathCodec, to PathCodec) string {
+	return to.Join(from.Split(paths))
+}
+
+// ConvertFolders returns a copy of 'folders' with their
+// Paths converted from the PathCodec 'from' to 'to'
+func ConvertFolders(folders []Folder, from PathCodec, to PathCodec) []Folder {
+	converted := make([]Folder, 0, len(folders))
+	for _, f := range folders {
+		f.Paths = ConvertPath(f.Paths, from, to)
+		converted = append(converted, f)
+	}
+
+	return converted
+}
+
+// LtreeToSlash returns the ltree style path 'paths', such
+// as "alpha.bravo", as a slash style path, "/alpha/bravo"
+func LtreeToSlash(paths string) string {
+	return ConvertPath(paths, DefaultPathCodec, SlashPathCodec)
+}
+
+// SlashToLtree returns the slash style path 'paths', such
+// as "/alpha/bravo", as an ltree style path, "alpha.bravo"
+func SlashToLtree(paths string) string {
+	return ConvertPath(paths, SlashPathCodec, DefaultPathCodec)
+}
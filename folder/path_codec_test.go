@@ -0,0 +1,228 @@
+package folder_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"testing/quick"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_PathCodec(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		names []string
+		paths string
+	}{
+		{
+			name:  "Plain names",
+			names: []string{"alpha", "bravo", "charlie"},
+			paths: "alpha.bravo.charlie",
+		},
+		{
+			name:  "Name with separators",
+			names: []string{"alpha", "v1.2 release"},
+			paths: `alpha.v1\.2 release`,
+		},
+		{
+			name:  "Name with escapes",
+			names: []string{`C:\temp`, "bravo"},
+			paths: `C:\\temp.bravo`,
+		},
+		{
+			name:  "Name ending in an escaped separator",
+			names: []string{"alpha.", "bravo"},
+			paths: `alpha\..bravo`,
+		},
+		{
+			name:  "Empty names",
+			names: []string{"", ""},
+			paths: ".",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if get := folder.JoinPath(tt.names); get != tt.paths {
+				t.Errorf("JoinPath() = %q, want %q", get, tt.paths)
+			}
+			if get := folder.SplitPath(tt.paths); !reflect.DeepEqual(get, tt.names) {
+				t.Errorf("SplitPath() = %q, want %q", get, tt.names)
+			}
+		})
+	}
+
+	if get := folder.SplitPath(`alpha\`); !reflect.DeepEqual(get, []string{`alpha\`}) {
+		t.Errorf("SplitPath() = %q, want the trailing escape kept", get)
+	}
+}
+
+func Test_folder_PathCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+	codecs := map[string]folder.PathCodec{
+		"Default":         folder.DefaultPathCodec,
+		"Slash and colon": {Separator: '/', Escape: ':'},
+	}
+
+	for name, codec := range codecs {
+		t.Run(name, func(t *testing.T) {
+			roundTrip := func(first string, rest []string) bool {
+				names := append([]string{first}, rest...)
+				return reflect.DeepEqual(codec.Split(codec.Join(names)), names)
+			}
+			if err := quick.Check(roundTrip, nil); err != nil {
+				t.Errorf("Split(Join()) is not the names joined: %v", err)
+			}
+
+			// An empty path is no parent, so the root name must not be empty
+			appended := func(first string, rest []string) bool {
+				if first == "" {
+					return true
+				}
+				paths := codec.Append("", first)
+				for _, name := range rest {
+					paths = codec.Append(paths, name)
+				}
+				return paths == codec.Join(append([]string{first}, rest...))
+			}
+			if err := quick.Check(appended, nil); err != nil {
+				t.Errorf("Append() differs from Join(): %v", err)
+			}
+		})
+	}
+
+	// Without an escape, only names without the separator survive
+	plain := folder.PathCodec{Separator: '.'}
+	roundTrip := func(first string, rest []string) bool {
+		names := append([]string{first}, rest...)
+		for _, name := range names {
+			if strings.ContainsRune(name, '.') {
+				return true
+			}
+		}
+		return reflect.DeepEqual(plain.Split(plain.Join(names)), names)
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Errorf("Split(Join()) is not the names joined without escapes: %v", err)
+	}
+}
+
+func Test_folder_EscapedNames(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver([]folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{Name: "v1.2 release", OrgId: orgID, Paths: `alpha.v1\.2 release`},
+		{Name: "notes", OrgId: orgID, Paths: `alpha.v1\.2 release.notes`},
+		{Name: "golf", OrgId: orgID, Paths: "golf"},
+	}, folder.WithNameValidator(folder.PermissiveNameValidator))
+
+	parent, err := f.GetParent(orgID, `alpha.v1\.2 release.notes`)
+	if err != nil || parent.Name != "v1.2 release" {
+		t.Errorf("GetParent() = %v, %v, want v1.2 release", parent, err)
+	}
+
+	if _, err := f.MoveFolder("v1.2 release", "golf"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+	want := []folder.Folder{
+		{Name: "v1.2 release", OrgId: orgID, Paths: `golf.v1\.2 release`},
+		{Name: "notes", OrgId: orgID, Paths: `golf.v1\.2 release.notes`},
+	}
+	get := f.GetAllChildFolders(orgID, "golf")
+	for i := range get {
+		get[i].UpdatedAt = want[i].UpdatedAt
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAllChildFolders() = %v, want %v", get, want)
+	}
+
+	created, err := f.CreateFolder(orgID, "draft.v2", `golf.v1\.2 release`)
+	if err != nil || created.Paths != `golf.v1\.2 release.draft\.v2` {
+		t.Errorf("CreateFolder() = %v, %v, want the name escaped in its path", created, err)
+	}
+}
+
+func Test_folder_ConvertPath(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		ltree string
+		slash string
+	}{
+		{
+			name:  "Root folder",
+			ltree: "alpha",
+			slash: "/alpha",
+		},
+		{
+			name:  "Nested folder",
+			ltree: "alpha.bravo.charlie",
+			slash: "/alpha/bravo/charlie",
+		},
+		{
+			name:  "Names with separators",
+			ltree: `alpha.v1\.2.a/b`,
+			slash: `/alpha/v1.2/a\/b`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if get := folder.LtreeToSlash(tt.ltree); get != tt.slash {
+				t.Errorf("LtreeToSlash() = %q, want %q", get, tt.slash)
+			}
+			if get := folder.SlashToLtree(tt.slash); get != tt.ltree {
+				t.Errorf("SlashToLtree() = %q, want %q", get, tt.ltree)
+			}
+		})
+	}
+}
+
+func Test_folder_SlashPaths(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	folders := folder.ConvertFolders(exampleFolders, folder.DefaultPathCodec, folder.SlashPathCodec)
+	f := folder.NewDriver(folders, folder.WithPathCodec(folder.SlashPathCodec))
+
+	parent, err := f.GetParent(orgID, "/alpha/bravo/charlie")
+	if err != nil || parent.Name != "bravo" {
+		t.Errorf("GetParent() = %v, %v, want bravo", parent, err)
+	}
+
+	if _, err := f.MoveFolder("bravo", "golf"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+	want := []string{"/golf/bravo", "/golf/bravo/charlie"}
+	get := []string{}
+	for _, child := range f.GetAllChildFolders(orgID, "golf") {
+		get = append(get, child.Paths)
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("GetAllChildFolders() = %v, want %v", get, want)
+	}
+
+	created, err := f.CreateFolder(orgID, "hotel", "")
+	if err != nil || created.Paths != "/hotel" {
+		t.Errorf("CreateFolder() = %v, %v, want path /hotel", created, err)
+	}
+
+	diff, err := f.RelativePath(orgID, "/golf", "/golf/bravo/charlie")
+	if err != nil || diff.Down != "bravo/charlie" {
+		t.Errorf("RelativePath() = %v, %v, want down bravo/charlie", diff, err)
+	}
+
+	if _, err := f.CreateFolder(orgID, "india", "/golf"); err != nil {
+		t.Fatalf("CreateFolder() = %v, want nil for error", err)
+	}
+	if _, err := f.CreateFolder(orgID, "juliet", "/golf/india"); err != nil {
+		t.Fatalf("CreateFolder() = %v, want nil for error", err)
+	}
+	diff, err = f.RelativePath(orgID, "/golf/bravo/charlie", "/golf/india/juliet")
+	if get := diff.Format(folder.SlashPathCodec); err != nil || get != "../../india/juliet" {
+		t.Errorf("RelativePath().Format() = %q, %v, want %q", get, err, "../../india/juliet")
+	}
+}
@@ -0,0 +1,176 @@
+package folder
+
+import (
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// SiblingPolicy is which names count as the same
+// among the folders sharing a parent
+type SiblingPolicy int
+
+const (
+	// SiblingNone adds no check of its own, so siblings may
+	// have names differing only in case. A folder is still
+	// never moved or created at the path of another, so
+	// siblings made by the driver never share a name.
+	SiblingNone SiblingPolicy = iota
+	// SiblingExact stops siblings having the same name
+	SiblingExact
+	// SiblingCaseFolded stops siblings having names which
+	// are the same ignoring case, such as "Reports" and
+	// "reports"
+	SiblingCaseFolded
+)
+
+// sameName returns whether 'a' and 'b' count as the
+// same name among siblings
+func (p SiblingPolicy) sameName(a string, b string) bool {
+	switch p {
+	case SiblingExact:
+		return a == b
+	case SiblingCaseFolded:
+		return strings.EqualFold(a, b)
+	default:
+		return false
+	}
+}
+
+// OrgPolicy limits the shape of the trees of an organization,
+// where a limit of 0 is no limit, and which names siblings
+// may share
+type OrgPolicy struct {
+	// MaxDepth is the most levels a tree may have, where
+	// a root folder on its own is 1 level
+	MaxDepth int
+	// MaxChildren is the most children a folder may have
+	MaxChildren int
+	// MaxFolders is the most folders the organization may have
+	MaxFolders int
+	// MaxPathLength is the most bytes the path of a folder
+	// may have
+	MaxPathLength int
+	// Siblings is which names siblings may not share,
+	// where root folders are siblings of each other
+	Siblings SiblingPolicy
+}
+
+// WithPolicy makes the driver enforce 'policy' on the
+// organization with 'orgID', in place of the default policy
+func WithPolicy(orgID uuid.UUID, policy OrgPolicy) DriverOption {
+	return func(d *driver) {
+		if d.policies == nil {
+			d.policies = map[uuid.UUID]OrgPolicy{}
+		}
+		d.policies[orgID] = policy
+	}
+}
+
+// WithDefaultPolicy makes the driver enforce 'policy' on
+// every organization without a policy of its own
+func WithDefaultPolicy(policy OrgPolicy) DriverOption {
+	return func(d *driver) {
+		d.defaultPolicy = policy
+	}
+}
+
+// policy returns the OrgPolicy enforced on the
+// organization with 'orgID'
+func (f *driver) policy(orgID uuid.UUID) OrgPolicy {
+	if policy, exists := f.policies[orgID]; exists {
+		return policy
+	}
+	return f.defaultPolicy
+}
+
+// checkLimit returns a LimitError if 'value' is over 'max',
+// where a 'max' of 0 is no limit
+func checkLimit(limit string, orgID uuid.UUID, ref string, max int, value int) error {
+	if max <= 0 || value <= max {
+		return nil
+	}
+
+	return &LimitError{
+		Limit: limit,
+		OrgID: orgID,
+		Ref:   ref,
+		Max:   max,
+		Value: value,
+	}
+}
+
+// checkMoveLimits returns a LimitError if moving 'srcFolder'
+// to 'dstFolder' would break the policy of its organization
+func (f *driver) checkMoveLimits(srcFolder *FileNode, dstFolder *FileNode) error {
+	orgID := srcFolder.file.OrgId
+	policy := f.policy(orgID)
+
+	if srcFolder.parent != dstFolder {
+		err := checkLimit(LimitChildren, orgID, dstFolder.file.Paths, policy.MaxChildren, len(dstFolder.children)+1)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The deepest and longest paths are found from the
+	// paths the move would give each folder
+	changes := PlanMove(srcFolder, dstFolder, f.codec)
+	deepest, longest := changes[0].NewPaths, changes[0].NewPaths
+	for _, c := range changes[1:] {
+		if len(f.codec.Split(c.NewPaths)) > len(f.codec.Split(deepest)) {
+			deepest = c.NewPaths
+		}
+		if len(c.NewPaths) > len(longest) {
+			longest = c.NewPaths
+		}
+	}
+	err := checkLimit(LimitDepth, orgID, deepest, policy.MaxDepth, len(f.codec.Split(deepest)))
+	if err != nil {
+		return err
+	}
+
+	return checkLimit(LimitPathLength, orgID, longest, policy.MaxPathLength, len(longest))
+}
+
+// checkCreateLimits returns a LimitError if adding a folder
+// with 'paths' below 'parentNode', or as a root folder if it
+// is nil, would break the policy of the organization
+func (f *driver) checkCreateLimits(orgID uuid.UUID, org Organization, parentNode *FileNode, paths string) error {
+	policy := f.policy(orgID)
+
+	err := checkLimit(LimitFolders, orgID, paths, policy.MaxFolders, len(org.folders)+1)
+	if err != nil {
+		return err
+	}
+	if parentNode != nil {
+		err := checkLimit(LimitChildren, orgID, parentNode.file.Paths, policy.MaxChildren, len(parentNode.children)+1)
+		if err != nil {
+			return err
+		}
+	}
+	err = checkLimit(LimitDepth, orgID, paths, policy.MaxDepth, len(f.codec.Split(paths)))
+	if err != nil {
+		return err
+	}
+
+	return checkLimit(LimitPathLength, orgID, paths, policy.MaxPathLength, len(paths))
+}
+
+// checkSiblings returns a NameConflictError if a folder with
+// 'name' among 'siblings' would have the same name as one of
+// them other than 'fileNode', under the policy of the organization
+func (f *driver) checkSiblings(orgID uuid.UUID, siblings []*FileNode, name string, fileNode *FileNode) error {
+	policy := f.policy(orgID)
+	for _, sibling := range siblings {
+		if sibling != fileNode && policy.Siblings.sameName(sibling.file.Name, name) {
+			return &NameConflictError{
+				OrgID:    orgID,
+				Name:     name,
+				Existing: sibling.file.Paths,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,207 @@
+package folder_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_OrgPolicy(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	tests := [...]struct {
+		name   string
+		policy folder.OrgPolicy
+		move   [2]string
+		create [2]string
+		err    error
+	}{
+		{
+			name:   "Within every limit",
+			policy: folder.OrgPolicy{MaxDepth: 4, MaxChildren: 2, MaxFolders: 7, MaxPathLength: 32},
+			move:   [2]string{"bravo", "delta"},
+			create: [2]string{"hotel", "golf"},
+		},
+		{
+			name:   "Move too deep",
+			policy: folder.OrgPolicy{MaxDepth: 3},
+			move:   [2]string{"bravo", "delta"},
+			err:    errors.New("error: depth of 4 is over the limit of 3"),
+		},
+		{
+			name:   "Move into a full folder",
+			policy: folder.OrgPolicy{MaxChildren: 1},
+			move:   [2]string{"echo", "bravo"},
+			err:    errors.New("error: children of 2 is over the limit of 1"),
+		},
+		{
+			name:   "Move makes a path too long",
+			policy: folder.OrgPolicy{MaxPathLength: 24},
+			move:   [2]string{"bravo", "delta"},
+			err:    errors.New("error: path length of 25 is over the limit of 24"),
+		},
+		{
+			name:   "Create too deep",
+			policy: folder.OrgPolicy{MaxDepth: 3},
+			create: [2]string{"hotel", "alpha.bravo.charlie"},
+			err:    errors.New("error: depth of 4 is over the limit of 3"),
+		},
+		{
+			name:   "Create too many folders",
+			policy: folder.OrgPolicy{MaxFolders: 6},
+			create: [2]string{"hotel", ""},
+			err:    errors.New("error: folders of 7 is over the limit of 6"),
+		},
+		{
+			name:   "Create in a full folder",
+			policy: folder.OrgPolicy{MaxChildren: 2},
+			create: [2]string{"hotel", "alpha"},
+			err:    errors.New("error: children of 3 is over the limit of 2"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...), folder.WithPolicy(orgID, tt.policy))
+
+			var err error
+			if tt.move[0] != "" {
+				_, dryRunErr := f.DryRunMove(orgID, tt.move[0], tt.move[1])
+				_, err = f.MoveFolder(tt.move[0], tt.move[1])
+				if (dryRunErr == nil) != (err == nil) {
+					t.Errorf("DryRunMove() = %v, want the same error as MoveFolder() = %v", dryRunErr, err)
+				}
+			}
+			if tt.create[0] != "" && err == nil {
+				_, err = f.CreateFolder(orgID, tt.create[0], tt.create[1])
+			}
+
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("OrgPolicy %v = %v, want nil for error", tt.policy, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err.Error() {
+				t.Errorf("OrgPolicy %v = %v, want %v for error", tt.policy, err, tt.err)
+			} else if !errors.Is(err, folder.ErrLimitExceeded) {
+				t.Errorf("OrgPolicy %v = %v, want a LimitError", tt.policy, err)
+			}
+		})
+	}
+}
+
+func Test_folder_OrgPolicy_Default(t *testing.T) {
+	t.Parallel()
+	otherOrgID := uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7")
+	f := folder.NewDriver(append([]folder.Folder{}, exampleFolders...),
+		folder.WithDefaultPolicy(folder.OrgPolicy{MaxFolders: 1}),
+		folder.WithPolicy(uuid.FromStringOrNil(folder.DefaultOrgID), folder.OrgPolicy{}))
+
+	if _, err := f.CreateFolder(uuid.FromStringOrNil(folder.DefaultOrgID), "hotel", ""); err != nil {
+		t.Errorf("CreateFolder() = %v, want nil for error with no limits", err)
+	}
+	limitErr := &folder.LimitError{}
+	_, err := f.CreateFolder(otherOrgID, "hotel", "foxtrot")
+	if !errors.As(err, &limitErr) || limitErr.Limit != folder.LimitFolders || limitErr.Value != 2 {
+		t.Errorf("CreateFolder() = %v, want the default folders limit", err)
+	}
+}
+
+func Test_folder_SiblingPolicy(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	folders := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{Name: "reports", OrgId: orgID, Paths: "alpha.reports"},
+		{Name: "golf", OrgId: orgID, Paths: "golf"},
+		{Name: "Reports", OrgId: orgID, Paths: "golf.Reports"},
+		{Name: "reports", OrgId: orgID, Paths: "golf.Reports.reports"},
+	}
+	tests := [...]struct {
+		name     string
+		siblings folder.SiblingPolicy
+		move     [2]string
+		create   [2]string
+		err      error
+	}{
+		{
+			name:     "No policy allows a move differing in case",
+			siblings: folder.SiblingNone,
+			move:     [2]string{"Reports", "alpha"},
+		},
+		{
+			name:     "No policy still stops a move onto the same name",
+			siblings: folder.SiblingNone,
+			move:     [2]string{"golf.Reports.reports", "alpha"},
+			err:      errors.New(`error: folder name "reports" conflicts with alpha.reports`),
+		},
+		{
+			name:     "Exact names conflict on a move",
+			siblings: folder.SiblingExact,
+			move:     [2]string{"golf.Reports.reports", "alpha"},
+			err:      errors.New(`error: folder name "reports" conflicts with alpha.reports`),
+		},
+		{
+			name:     "Exact names allow a move differing in case",
+			siblings: folder.SiblingExact,
+			move:     [2]string{"Reports", "alpha"},
+		},
+		{
+			name:     "Case folded names conflict on a move",
+			siblings: folder.SiblingCaseFolded,
+			move:     [2]string{"Reports", "alpha"},
+			err:      errors.New(`error: folder name "Reports" conflicts with alpha.reports`),
+		},
+		{
+			name:     "Exact names allow a create differing in case",
+			siblings: folder.SiblingExact,
+			create:   [2]string{"REPORTS", "alpha"},
+		},
+		{
+			name:     "Case folded names conflict on a create",
+			siblings: folder.SiblingCaseFolded,
+			create:   [2]string{"REPORTS", "alpha"},
+			err:      errors.New(`error: folder name "REPORTS" conflicts with alpha.reports`),
+		},
+		{
+			name:     "Case folded root folders conflict",
+			siblings: folder.SiblingCaseFolded,
+			create:   [2]string{"Golf", ""},
+			err:      errors.New(`error: folder name "Golf" conflicts with golf`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := folder.OrgPolicy{Siblings: tt.siblings}
+			f := folder.NewDriver(append([]folder.Folder{}, folders...), folder.WithPolicy(orgID, policy))
+
+			var err error
+			if tt.move[0] != "" {
+				_, dryRunErr := f.DryRunMove(orgID, tt.move[0], tt.move[1])
+				_, err = f.MoveFolder(tt.move[0], tt.move[1])
+				if (dryRunErr == nil) != (err == nil) {
+					t.Errorf("DryRunMove() = %v, want the same error as MoveFolder() = %v", dryRunErr, err)
+				}
+			}
+			if tt.create[0] != "" {
+				_, err = f.CreateFolder(orgID, tt.create[0], tt.create[1])
+			}
+
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("SiblingPolicy %v = %v, want nil for error", tt.siblings, err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.err.Error() {
+				t.Errorf("SiblingPolicy %v = %v, want %v for error", tt.siblings, err, tt.err)
+			} else if !errors.Is(err, folder.ErrNameConflict) {
+				t.Errorf("SiblingPolicy %v = %v, want a NameConflictError", tt.siblings, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package folder
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// PathDiff describes how to get from one folder to another
+// through their lowest common ancestor
+type PathDiff struct {
+	// Up is the number of levels from the first folder
+	// up to the common ancestor
+	Up int `json:"up"`
+	// Down is the path from the common ancestor down to the
+	// second folder, written with the PathCodec of the driver,
+	// and empty if it is the common ancestor
+	Down string `json:"down"`
+}
+
+// String returns the PathDiff as a path where "^" goes up
+// a level, such as "^.^.bravo.charlie", for a Down written
+// with the DefaultPathCodec
+func (p PathDiff) String() string {
+	return p.Format(DefaultPathCodec)
+}
+
+// Format returns the PathDiff as a path for a Down written
+// with 'codec'. Going up a level is "^" when names are
+// separated by '.', and ".." otherwise, such as "../../b/c"
+// for the SlashPathCodec.
+func (p PathDiff) Format(codec PathCodec) string {
+	up := ".."
+	if codec.Separator == '.' {
+		up = "^"
+	}
+
+	sections := []string{}
+	for i := 0; i < p.Up; i++ {
+		sections = append(sections, up)
+	}
+	if p.Down != "" {
+		sections = append(sections, p.Down)
+	}
+
+	return strings.Join(sections, string(codec.Separator))
+}
+
+// NodeDepth returns the number of FileNodes above
+// 'fileNode', following its parent links
+func NodeDepth(fileNode *FileNode) int {
+	depth := 0
+	for parent := fileNode.parent; parent != nil; parent = parent.parent {
+		depth++
+	}
+
+	return depth
+}
+
+// IsDescendant returns whether 'ancestor' is above
+// 'fileNode', following its parent links
+func IsDescendant(fileNode *FileNode, ancestor *FileNode) bool {
+	for parent := fileNode.parent; parent != nil; parent = parent.parent {
+		if parent == ancestor {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindCommonAncestor returns the deepest FileNode which is
+// 'a', 'b' or above both of them, or nil if they are in
+// different trees
+func FindCommonAncestor(a *FileNode, b *FileNode) *FileNode {
+	depthA, depthB := NodeDepth(a), NodeDepth(b)
+	for ; depthA > depthB; depthA-- {
+		a = a.parent
+	}
+	for ; depthB > depthA; depthB-- {
+		b = b.parent
+	}
+
+	for a != b {
+		a, b = a.parent, b.parent
+	}
+
+	return a
+}
+
+// relate returns the FileNodes of 'a' and 'b' and their
+// lowest common ancestor, or an error if any of them does
+// not exist
+func (f *driver) relate(orgID uuid.UUID, a string, b string) (*FileNode, *FileNode, *FileNode, error) {
+	nodeA, err := f.findNode(orgID, a)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	nodeB, err := f.findNode(orgID, b)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	ancestor := FindCommonAncestor(nodeA, nodeB)
+	if ancestor == nil {
+		return nil, nil, nil, &NotFoundError{Kind: KindCommonAncestor, OrgID: orgID, Ref: a}
+	}
+
+	return nodeA, nodeB, ancestor, nil
+}
+
+// LowestCommonAncestor returns the deepest Folder which is
+// either of the two folders or above both of them
+func (f *driver) LowestCommonAncestor(orgID uuid.UUID, a string, b string) (Folder, error) {
+	_, _, ancestor, err := f.relate(orgID, a, b)
+	if err != nil {
+		return Folder{}, err
+	}
+
+	return ancestor.file.clone(), nil
+}
+
+// Distance returns the number of parent links on the way
+// from one folder up to their common ancestor and back
+// down to the other
+func (f *driver) Distance(orgID uuid.UUID, a string, b string) (int, error) {
+	nodeA, nodeB, ancestor, err := f.relate(orgID, a, b)
+	if err != nil {
+		return 0, err
+	}
+
+	ancestorDepth := NodeDepth(ancestor)
+	return NodeDepth(nodeA) + NodeDepth(nodeB) - 2*ancestorDepth, nil
+}
+
+// RelativePath returns the PathDiff from folder 'a' to
+// folder 'b', taking the names below their common ancestor
+// from the parents of 'b', as the path of a folder kept
+// without its parents names folders which do not exist
+func (f *driver) RelativePath(orgID uuid.UUID, a string, b string) (PathDiff, error) {
+	nodeA, nodeB, ancestor, err := f.relate(orgID, a, b)
+	if err != nil {
+		return PathDiff{}, err
+	}
+
+	names := []string{}
+	for fileNode := nodeB; fileNode != ancestor; fileNode = fileNode.parent {
+		names = append(names, fileNode.file.Name)
+	}
+	slices.Reverse(names)
+
+	return PathDiff{
+		Up:   NodeDepth(nodeA) - NodeDepth(ancestor),
+		Down: f.codec.joinNames(names),
+	}, nil
+}
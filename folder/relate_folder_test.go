@@ -0,0 +1,126 @@
+package folder_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_RelateFolders(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name     string
+		orgID    uuid.UUID
+		a        string
+		b        string
+		ancestor string
+		distance int
+		relative string
+		err      error
+	}{
+		{
+			name:     "Cousins",
+			orgID:    uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:        "alpha.bravo.charlie",
+			b:        "alpha.delta.echo",
+			ancestor: "alpha",
+			distance: 4,
+			relative: "^.^.delta.echo",
+		},
+		{
+			name:     "Siblings",
+			orgID:    uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:        "alpha.bravo",
+			b:        "alpha.delta",
+			ancestor: "alpha",
+			distance: 2,
+			relative: "^.delta",
+		},
+		{
+			name:     "Ancestor to descendant",
+			orgID:    uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:        "alpha",
+			b:        "alpha.bravo.charlie",
+			ancestor: "alpha",
+			distance: 2,
+			relative: "bravo.charlie",
+		},
+		{
+			name:     "Descendant to ancestor",
+			orgID:    uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:        "alpha.delta.echo",
+			b:        "alpha",
+			ancestor: "alpha",
+			distance: 2,
+			relative: "^.^",
+		},
+		{
+			name:     "Same folder",
+			orgID:    uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:        "alpha.delta",
+			b:        "alpha.delta",
+			ancestor: "alpha.delta",
+			distance: 0,
+			relative: "",
+		},
+		{
+			name:  "Different trees",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:     "alpha.bravo",
+			b:     "golf",
+			err:   errors.New("error: common ancestor does not exist"),
+		},
+		{
+			name:  "Folder in a different org",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			a:     "alpha",
+			b:     "foxtrot",
+			err:   errors.New("error: folder does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+
+			ancestor, err := f.LowestCommonAncestor(tt.orgID, tt.a, tt.b)
+			checkNotFound(t, "LowestCommonAncestor()", err, tt.err)
+			if ancestor.Paths != tt.ancestor {
+				t.Errorf("LowestCommonAncestor() = %v, want %v", ancestor.Paths, tt.ancestor)
+			}
+
+			distance, err := f.Distance(tt.orgID, tt.a, tt.b)
+			checkNotFound(t, "Distance()", err, tt.err)
+			if distance != tt.distance {
+				t.Errorf("Distance() = %v, want %v", distance, tt.distance)
+			}
+
+			relative, err := f.RelativePath(tt.orgID, tt.a, tt.b)
+			checkNotFound(t, "RelativePath()", err, tt.err)
+			if relative.String() != tt.relative {
+				t.Errorf("RelativePath() = %v, want %v", relative, tt.relative)
+			}
+		})
+	}
+}
+
+func Test_folder_RelativePath_Orphans(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver([]folder.Folder{
+		{Name: "z", OrgId: orgID, Paths: "p.q.z"},
+		{Name: "w", OrgId: orgID, Paths: "p.q.z.w"},
+	})
+
+	// z is kept as a root folder, so the names in its path
+	// above it are not counted
+	relative, err := f.RelativePath(orgID, "p.q.z", "p.q.z.w")
+	if err != nil {
+		t.Fatalf("RelativePath() = %v, want nil for error", err)
+	}
+	if want := (folder.PathDiff{Down: "w"}); relative != want {
+		t.Errorf("RelativePath() = %v, want %v", relative, want)
+	}
+}
@@ -0,0 +1,366 @@
+package folder
+
+import (
+	"fmt"
+
+	"github.com/gofrs/uuid"
+)
+
+// RepairKind is how Repair changed a folder
+type RepairKind string
+
+const (
+	RepairRemoved  RepairKind = "removed"
+	RepairRenamed  RepairKind = "renamed"
+	RepairCreated  RepairKind = "created"
+	RepairDetached RepairKind = "detached"
+	RepairRepathed RepairKind = "repathed"
+)
+
+// RepairAction is a single change made by Repair
+type RepairAction struct {
+	Kind  RepairKind `json:"kind"`
+	OrgId uuid.UUID  `json:"org_id"`
+	// Name is the name of the folder after the change
+	Name string `json:"name"`
+	// OldName is the name before a rename
+	OldName  string `json:"old_name,omitempty"`
+	OldPaths string `json:"old_paths,omitempty"`
+	NewPaths string `json:"new_paths,omitempty"`
+	// Reason explains why the change was made
+	Reason string `json:"reason"`
+}
+
+// String returns the action as a line of text
+func (a RepairAction) String() string {
+	switch a.Kind {
+	case RepairRemoved:
+		return fmt.Sprintf("%-8s %s %s: %s", a.Kind, a.OrgId, a.OldPaths, a.Reason)
+	case RepairRenamed:
+		return fmt.Sprintf("%-8s %s %s -> %s: %s", a.Kind, a.OrgId, a.OldName, a.Name, a.Reason)
+	case RepairRepathed:
+		return fmt.Sprintf("%-8s %s %s -> %s: %s", a.Kind, a.OrgId, a.OldPaths, a.NewPaths, a.Reason)
+	default:
+		return fmt.Sprintf("%-8s %s %s: %s", a.Kind, a.OrgId, a.NewPaths, a.Reason)
+	}
+}
+
+// DuplicatePolicy is what Repair does with a folder with
+// the same name as an earlier folder in its organization
+type DuplicatePolicy int
+
+const (
+	// DuplicateRename gives the folder a name with the
+	// lowest free suffix, as SuffixName does
+	DuplicateRename DuplicatePolicy = iota
+	// DuplicateRemove leaves the folder out
+	DuplicateRemove
+)
+
+// RepairPolicy is how Repair fixes the folders it is given
+type RepairPolicy struct {
+	Duplicates DuplicatePolicy
+	// Orphans is what is done with a folder whose parent does
+	// not exist. OrphanKeep makes it a root folder, and
+	// OrphanReject leaves it out.
+	Orphans OrphanPolicy
+}
+
+// Repair returns 'folders' fixed so that a driver loads every
+// one of them as their paths describe, along with the changes
+// made, in the order they were made. Paths use the
+// DefaultPathCodec.
+//
+// A folder with the same name and path as an earlier folder is
+// always removed, and one with only the same name is handled
+// by 'policy'. The parent of each folder is the folder whose
+// path its path was given below, so a renamed folder keeps its
+// children, or failing that the folder named second last in its
+// path, as it is for a driver. A folder
+// whose parents lead back to itself is detached as a root
+// folder, breaking the cycle. The path of every folder is then
+// recomputed from its parents. Folders created for orphans
+// come after the folders given.
+func Repair(folders []Folder, policy RepairPolicy) ([]Folder, []RepairAction) {
+	r := repairer{
+		policy:  policy,
+		actions: []RepairAction{},
+		byName:  map[folderKey]int{},
+		byPath:  map[pathKey]int{},
+	}
+	r.dedupe(folders)
+	r.adoptOrphans()
+	r.link()
+	r.breakCycles()
+	return r.repath(), r.actions
+}
+
+// repairer holds the folders being repaired by Repair,
+// which are only ever appended to, so they are referred
+// to by their index
+type repairer struct {
+	policy  RepairPolicy
+	actions []RepairAction
+
+	folders  []Folder
+	oldPaths []string
+	// reasons are why each folder has no parent, or has
+	// a new name, used to explain changes to its path
+	reasons []string
+	removed []bool
+	created []bool
+	// parents are the indexes of the parent of each
+	// folder, or -1 for a root folder
+	parents []int
+	byName  map[folderKey]int
+	// byPath is the index of the folder given with each
+	// path, or -1 for a duplicate which was removed
+	byPath map[pathKey]int
+}
+
+// add adds 'f' to the folders being repaired, with the
+// reason for any change to its path
+func (r *repairer) add(f Folder, created bool, reason string) {
+	r.folders = append(r.folders, f)
+	r.oldPaths = append(r.oldPaths, f.Paths)
+	r.reasons = append(r.reasons, reason)
+	r.removed = append(r.removed, false)
+	r.created = append(r.created, created)
+	r.byName[folderKey{orgID: f.OrgId, name: f.Name}] = len(r.folders) - 1
+	if _, exists := r.byPath[pathKey{orgID: f.OrgId, paths: f.Paths}]; !exists {
+		r.byPath[pathKey{orgID: f.OrgId, paths: f.Paths}] = len(r.folders) - 1
+	}
+}
+
+// create adds a new folder with 'name' and 'paths' in the
+// organization with 'orgID', for the reason given
+func (r *repairer) create(orgID uuid.UUID, name string, paths string, reason string) {
+	r.add(Folder{
+		ID:    uuid.Must(uuid.NewV4()),
+		Name:  name,
+		OrgId: orgID,
+		Paths: paths,
+	}, true, "")
+	r.actions = append(r.actions, RepairAction{
+		Kind:     RepairCreated,
+		OrgId:    orgID,
+		Name:     name,
+		NewPaths: paths,
+		Reason:   reason,
+	})
+}
+
+// dedupe adds 'folders', removing or renaming those with
+// the same name as an earlier folder in their organization
+func (r *repairer) dedupe(folders []Folder) {
+	// taken holds every name given, so that a folder is not
+	// renamed to the name of a folder that comes later
+	taken := map[folderKey]bool{}
+	for _, f := range folders {
+		taken[folderKey{orgID: f.OrgId, name: f.Name}] = true
+	}
+
+	for _, f := range folders {
+		first, exists := r.byName[folderKey{orgID: f.OrgId, name: f.Name}]
+		if !exists {
+			r.add(f, false, "")
+			continue
+		}
+
+		firstPaths := r.folders[first].Paths
+		if firstPaths == f.Paths || r.policy.Duplicates == DuplicateRemove {
+			r.actions = append(r.actions, RepairAction{
+				Kind:     RepairRemoved,
+				OrgId:    f.OrgId,
+				Name:     f.Name,
+				OldPaths: f.Paths,
+				Reason:   "duplicate of the folder at " + firstPaths,
+			})
+			if _, exists := r.byPath[pathKey{orgID: f.OrgId, paths: f.Paths}]; !exists {
+				r.byPath[pathKey{orgID: f.OrgId, paths: f.Paths}] = -1
+			}
+			continue
+		}
+
+		name := SuffixName(f.Name, func(name string) bool {
+			return taken[folderKey{orgID: f.OrgId, name: name}]
+		})
+		taken[folderKey{orgID: f.OrgId, name: name}] = true
+		r.actions = append(r.actions, RepairAction{
+			Kind:     RepairRenamed,
+			OrgId:    f.OrgId,
+			Name:     name,
+			OldName:  f.Name,
+			OldPaths: f.Paths,
+			Reason:   "name is used by the folder at " + firstPaths,
+		})
+		f.Name = name
+		r.add(f, false, "its name was changed")
+	}
+}
+
+// parentIndex returns the index of the parent named in the
+// path of the folder with 'i', and whether one is named
+func (r *repairer) parentIndex(i int) (int, bool) {
+	f := r.folders[i]
+	sections := SplitPath(f.Paths)
+	if len(sections) < 2 {
+		return -1, false
+	}
+	if j, exists := r.byPath[pathKey{orgID: f.OrgId, paths: JoinPath(sections[:len(sections)-1])}]; exists {
+		if j < 0 || r.removed[j] {
+			return -1, true
+		}
+		return j, true
+	}
+	if j, exists := r.byName[folderKey{orgID: f.OrgId, name: sections[len(sections)-2]}]; exists && !r.removed[j] {
+		return j, true
+	}
+
+	return -1, true
+}
+
+// adoptOrphans handles the folders whose parent does not
+// exist using the OrphanPolicy. Folders created for them
+// are checked in turn, and removing a folder makes orphans
+// of its children, so removing repeats until none are left.
+func (r *repairer) adoptOrphans() {
+	for again := true; again; {
+		again = false
+		for i := 0; i < len(r.folders); i++ {
+			if r.removed[i] {
+				continue
+			}
+			if j, named := r.parentIndex(i); named && j < 0 {
+				r.adopt(i)
+				again = r.policy.Orphans == OrphanReject
+			}
+		}
+	}
+}
+
+// adopt handles the folder with 'i', whose parent
+// does not exist, using the OrphanPolicy
+func (r *repairer) adopt(i int) {
+	f := r.folders[i]
+	sections := SplitPath(f.Paths)
+	reason := fmt.Sprintf("parent %s of %s does not exist", sections[len(sections)-2], f.Paths)
+
+	switch r.policy.Orphans {
+	case OrphanReject:
+		r.removed[i] = true
+		r.actions = append(r.actions, RepairAction{
+			Kind:     RepairRemoved,
+			OrgId:    f.OrgId,
+			Name:     f.Name,
+			OldPaths: f.Paths,
+			Reason:   reason,
+		})
+	case OrphanLostFound:
+		if _, exists := r.byName[folderKey{orgID: f.OrgId, name: LostFoundName}]; !exists {
+			r.create(f.OrgId, LostFoundName, AppendPath("", LostFoundName), "holds folders whose parents do not exist")
+		}
+		r.folders[i].Paths = AppendPath(AppendPath("", LostFoundName), f.Name)
+		r.reasons[i] = reason
+	case OrphanCreateAncestors:
+		for j := len(sections) - 2; j >= 0; j-- {
+			if _, exists := r.byName[folderKey{orgID: f.OrgId, name: sections[j]}]; exists {
+				break
+			}
+			r.create(f.OrgId, sections[j], JoinPath(sections[:j+1]), reason)
+		}
+	default:
+		r.reasons[i] = reason
+	}
+}
+
+// link finds the parent of every folder
+func (r *repairer) link() {
+	r.parents = make([]int, len(r.folders))
+	for i := range r.folders {
+		r.parents[i], _ = r.parentIndex(i)
+	}
+}
+
+// breakCycles detaches a folder in every cycle of parents
+// as a root folder
+func (r *repairer) breakCycles() {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(r.folders))
+
+	for i := range r.folders {
+		walked := []int{}
+		j := i
+		for ; j >= 0 && state[j] == unvisited; j = r.parents[j] {
+			state[j] = visiting
+			walked = append(walked, j)
+		}
+		if j >= 0 && state[j] == visiting {
+			r.parents[j] = -1
+			r.reasons[j] = "its parents formed a cycle"
+			r.actions = append(r.actions, RepairAction{
+				Kind:     RepairDetached,
+				OrgId:    r.folders[j].OrgId,
+				Name:     r.folders[j].Name,
+				OldPaths: r.oldPaths[j],
+				NewPaths: AppendPath("", r.folders[j].Name),
+				Reason:   "its parents led back to itself",
+			})
+		}
+		for _, k := range walked {
+			state[k] = visited
+		}
+	}
+}
+
+// repath recomputes the path of every folder from its
+// parents, and returns the folders which were not removed
+func (r *repairer) repath() []Folder {
+	done := make([]bool, len(r.folders))
+	var paths func(i int) string
+	paths = func(i int) string {
+		if !done[i] {
+			parentPaths := ""
+			if r.parents[i] >= 0 {
+				parentPaths = paths(r.parents[i])
+			}
+			r.folders[i].Paths = AppendPath(parentPaths, r.folders[i].Name)
+			done[i] = true
+		}
+		return r.folders[i].Paths
+	}
+
+	repaired := []Folder{}
+	for i := range r.folders {
+		if r.removed[i] {
+			continue
+		}
+		newPaths := paths(i)
+		repaired = append(repaired, r.folders[i])
+		if r.created[i] || newPaths == r.oldPaths[i] {
+			continue
+		}
+
+		reason := r.reasons[i]
+		if reason == "" {
+			reason = "its path did not match its parent"
+			if parent := r.parents[i]; parent >= 0 && r.folders[parent].Paths != r.oldPaths[parent] {
+				reason = "the path of its parent was changed"
+			}
+		}
+		r.actions = append(r.actions, RepairAction{
+			Kind:     RepairRepathed,
+			OrgId:    r.folders[i].OrgId,
+			Name:     r.folders[i].Name,
+			OldPaths: r.oldPaths[i],
+			NewPaths: newPaths,
+			Reason:   reason,
+		})
+	}
+
+	return repaired
+}
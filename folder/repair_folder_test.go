@@ -0,0 +1,173 @@
+package folder_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_Repair(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	tests := [...]struct {
+		name    string
+		folders []folder.Folder
+		policy  folder.RepairPolicy
+		paths   []string
+		actions []folder.RepairAction
+	}{
+		{
+			name: "Sound folders",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+			},
+			paths:   []string{"alpha", "alpha.bravo"},
+			actions: []folder.RepairAction{},
+		},
+		{
+			name: "Path not ending in the name",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.charlie"},
+				{Name: "delta", OrgId: orgID, Paths: "bravo.delta"},
+			},
+			paths: []string{"alpha", "alpha.bravo", "alpha.bravo.delta"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "bravo", OldPaths: "alpha.charlie", NewPaths: "alpha.bravo", Reason: "its path did not match its parent"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "delta", OldPaths: "bravo.delta", NewPaths: "alpha.bravo.delta", Reason: "the path of its parent was changed"},
+			},
+		},
+		{
+			name: "Duplicates renamed",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+				{Name: "bravo", OrgId: orgID, Paths: "golf.bravo"},
+			},
+			paths: []string{"alpha", "alpha.bravo", "golf", "golf.bravo-2"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRemoved, OrgId: orgID, Name: "alpha", OldPaths: "alpha", Reason: "duplicate of the folder at alpha"},
+				{Kind: folder.RepairRenamed, OrgId: orgID, Name: "bravo-2", OldName: "bravo", OldPaths: "golf.bravo", Reason: "name is used by the folder at alpha.bravo"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "bravo-2", OldPaths: "golf.bravo", NewPaths: "golf.bravo-2", Reason: "its name was changed"},
+			},
+		},
+		{
+			name: "Renamed duplicate keeps its children",
+			folders: []folder.Folder{
+				{Name: "a", OrgId: orgID, Paths: "a"},
+				{Name: "x", OrgId: orgID, Paths: "a.x"},
+				{Name: "c", OrgId: orgID, Paths: "a.x.c"},
+				{Name: "b", OrgId: orgID, Paths: "b"},
+				{Name: "x", OrgId: orgID, Paths: "b.x"},
+				{Name: "d", OrgId: orgID, Paths: "b.x.d"},
+			},
+			paths: []string{"a", "a.x", "a.x.c", "b", "b.x-2", "b.x-2.d"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRenamed, OrgId: orgID, Name: "x-2", OldName: "x", OldPaths: "b.x", Reason: "name is used by the folder at a.x"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "x-2", OldPaths: "b.x", NewPaths: "b.x-2", Reason: "its name was changed"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "d", OldPaths: "b.x.d", NewPaths: "b.x-2.d", Reason: "the path of its parent was changed"},
+			},
+		},
+		{
+			name: "Duplicates removed",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+				{Name: "golf", OrgId: orgID, Paths: "golf"},
+				{Name: "alpha", OrgId: orgID, Paths: "golf.alpha"},
+			},
+			policy: folder.RepairPolicy{Duplicates: folder.DuplicateRemove},
+			paths:  []string{"alpha", "golf"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRemoved, OrgId: orgID, Name: "alpha", OldPaths: "golf.alpha", Reason: "duplicate of the folder at alpha"},
+			},
+		},
+		{
+			name: "Cycle",
+			folders: []folder.Folder{
+				{Name: "alpha", OrgId: orgID, Paths: "bravo.alpha"},
+				{Name: "bravo", OrgId: orgID, Paths: "alpha.bravo"},
+			},
+			paths: []string{"alpha", "alpha.bravo"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairDetached, OrgId: orgID, Name: "alpha", OldPaths: "bravo.alpha", NewPaths: "alpha", Reason: "its parents led back to itself"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "alpha", OldPaths: "bravo.alpha", NewPaths: "alpha", Reason: "its parents formed a cycle"},
+			},
+		},
+		{
+			name: "Orphans kept as root folders",
+			folders: []folder.Folder{
+				{Name: "zulu", OrgId: orgID, Paths: "x.zulu"},
+				{Name: "kilo", OrgId: orgID, Paths: "x.zulu.kilo"},
+			},
+			paths: []string{"zulu", "zulu.kilo"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "zulu", OldPaths: "x.zulu", NewPaths: "zulu", Reason: "parent x of x.zulu does not exist"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "kilo", OldPaths: "x.zulu.kilo", NewPaths: "zulu.kilo", Reason: "the path of its parent was changed"},
+			},
+		},
+		{
+			name: "Orphans removed",
+			folders: []folder.Folder{
+				{Name: "kilo", OrgId: orgID, Paths: "x.zulu.kilo"},
+				{Name: "zulu", OrgId: orgID, Paths: "x.zulu"},
+			},
+			policy: folder.RepairPolicy{Orphans: folder.OrphanReject},
+			paths:  []string{},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairRemoved, OrgId: orgID, Name: "zulu", OldPaths: "x.zulu", Reason: "parent x of x.zulu does not exist"},
+				{Kind: folder.RepairRemoved, OrgId: orgID, Name: "kilo", OldPaths: "x.zulu.kilo", Reason: "parent zulu of x.zulu.kilo does not exist"},
+			},
+		},
+		{
+			name: "Orphans moved to lost and found",
+			folders: []folder.Folder{
+				{Name: "zulu", OrgId: orgID, Paths: "x.zulu"},
+				{Name: "kilo", OrgId: orgID, Paths: "x.zulu.kilo"},
+			},
+			policy: folder.RepairPolicy{Orphans: folder.OrphanLostFound},
+			paths:  []string{"lost_found.zulu", "lost_found.zulu.kilo", "lost_found"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairCreated, OrgId: orgID, Name: "lost_found", NewPaths: "lost_found", Reason: "holds folders whose parents do not exist"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "zulu", OldPaths: "x.zulu", NewPaths: "lost_found.zulu", Reason: "parent x of x.zulu does not exist"},
+				{Kind: folder.RepairRepathed, OrgId: orgID, Name: "kilo", OldPaths: "x.zulu.kilo", NewPaths: "lost_found.zulu.kilo", Reason: "the path of its parent was changed"},
+			},
+		},
+		{
+			name: "Orphans given their missing ancestors",
+			folders: []folder.Folder{
+				{Name: "zulu", OrgId: orgID, Paths: "x.y.zulu"},
+			},
+			policy: folder.RepairPolicy{Orphans: folder.OrphanCreateAncestors},
+			paths:  []string{"x.y.zulu", "x.y", "x"},
+			actions: []folder.RepairAction{
+				{Kind: folder.RepairCreated, OrgId: orgID, Name: "y", NewPaths: "x.y", Reason: "parent y of x.y.zulu does not exist"},
+				{Kind: folder.RepairCreated, OrgId: orgID, Name: "x", NewPaths: "x", Reason: "parent y of x.y.zulu does not exist"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired, actions := folder.Repair(tt.folders, tt.policy)
+
+			paths := []string{}
+			for _, f := range repaired {
+				paths = append(paths, f.Paths)
+			}
+			if !reflect.DeepEqual(paths, tt.paths) {
+				t.Errorf("Repair() = %v, want paths %v", paths, tt.paths)
+			}
+			if !reflect.DeepEqual(actions, tt.actions) {
+				t.Errorf("Repair() actions = %v, want %v", actions, tt.actions)
+			}
+			if _, err := folder.LoadDriver(repaired, folder.WithOrphanPolicy(folder.OrphanReject)); err != nil {
+				t.Errorf("LoadDriver() = %v, want the repaired folders to load", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package folder
+
+import (
+	"errors"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// SearchMode is how Search matches a query against
+// folder names
+type SearchMode int
+
+const (
+	// SearchExact matches names equal to the query
+	SearchExact SearchMode = iota
+	// SearchPrefix matches names starting with the query
+	SearchPrefix
+	// SearchSubstring matches names containing the query
+	SearchSubstring
+	// SearchGlob matches names against the query as a
+	// pattern, using the syntax of path.Match
+	SearchGlob
+	// SearchFuzzy matches names at least Threshold similar
+	// to the query, ranked by similarity first
+	SearchFuzzy
+)
+
+// SearchOptions changes how Search matches folders
+type SearchOptions struct {
+	Mode SearchMode
+	// CaseInsensitive ignores the case of letters
+	CaseInsensitive bool
+	// Subtree, if set, is the path or name of a folder
+	// whose descendants are the only folders searched
+	Subtree string
+	// Limit is the most folders to return, where 0 is no limit
+	Limit int
+	// Threshold is the Similarity needed by SearchFuzzy,
+	// where 0 uses DefaultFuzzyThreshold
+	Threshold float64
+}
+
+// Search returns the folders in the organization whose names
+// match 'query', ranked by similarity for SearchFuzzy, then by
+// depth and then by path. Exact and prefix searches only look
+// at the matching part of the index.
+func (f *driver) Search(orgID uuid.UUID, query string, opts SearchOptions) ([]Folder, error) {
+	org, err := f.findOrg(orgID)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	var subtree *FileNode
+	if opts.Subtree != "" {
+		subtree, err = f.findNode(orgID, opts.Subtree)
+		if err != nil {
+			return []Folder{}, err
+		}
+	}
+
+	score, err := nameScorer(query, opts)
+	if err != nil {
+		return []Folder{}, err
+	}
+
+	index := org.index
+	if index == nil {
+		index = NewFolderIndex(org.folders)
+	}
+	candidates := index.byName
+	if opts.Mode == SearchExact || opts.Mode == SearchPrefix {
+		candidates = index.withPrefix(strings.ToLower(query))
+	}
+
+	type result struct {
+		fileNode *FileNode
+		score    float64
+		depth    int
+	}
+	results := []result{}
+	for _, entry := range candidates {
+		s, ok := score(entry.fileNode.file.Name)
+		if !ok {
+			continue
+		}
+		if subtree != nil && !IsDescendant(entry.fileNode, subtree) {
+			continue
+		}
+		results = append(results, result{
+			fileNode: entry.fileNode,
+			score:    s,
+			depth:    NodeDepth(entry.fileNode),
+		})
+	}
+
+	slices.SortFunc(results, func(a result, b result) int {
+		if a.score != b.score {
+			if a.score > b.score {
+				return -1
+			}
+			return 1
+		}
+		if a.depth != b.depth {
+			return a.depth - b.depth
+		}
+		return strings.Compare(a.fileNode.file.Paths, b.fileNode.file.Paths)
+	})
+	if opts.Limit > 0 && len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+
+	folders := []Folder{}
+	for _, r := range results {
+		folders = append(folders, r.fileNode.file.clone())
+	}
+
+	return folders, nil
+}
+
+// nameScorer returns a function reporting whether a folder
+// name matches 'query' in the given mode, and how closely.
+// Every mode other than SearchFuzzy scores all matches as 1.
+func nameScorer(query string, opts SearchOptions) (func(string) (float64, bool), error) {
+	fold := func(s string) string {
+		return s
+	}
+	if opts.CaseInsensitive {
+		fold = strings.ToLower
+	}
+	query = fold(query)
+
+	exact := func(match func(name string) bool) func(string) (float64, bool) {
+		return func(name string) (float64, bool) {
+			return 1, match(fold(name))
+		}
+	}
+
+	switch opts.Mode {
+	case SearchExact:
+		return exact(func(name string) bool {
+			return name == query
+		}), nil
+	case SearchPrefix:
+		return exact(func(name string) bool {
+			return strings.HasPrefix(name, query)
+		}), nil
+	case SearchSubstring:
+		return exact(func(name string) bool {
+			return strings.Contains(name, query)
+		}), nil
+	case SearchGlob:
+		if _, err := path.Match(query, ""); err != nil {
+			return nil, errors.New("error: invalid search pattern")
+		}
+		return exact(func(name string) bool {
+			matched, _ := path.Match(query, name)
+			return matched
+		}), nil
+	case SearchFuzzy:
+		threshold := opts.Threshold
+		if threshold == 0 {
+			threshold = DefaultFuzzyThreshold
+		}
+		return func(name string) (float64, bool) {
+			s := Similarity(query, fold(name))
+			return s, s >= threshold
+		}, nil
+	}
+
+	return nil, errors.New("error: unknown search mode")
+}
@@ -0,0 +1,248 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+// searchFolders has names sharing prefixes at different depths
+var searchFolders = []folder.Folder{
+	{
+		Name:  "Reports",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "Reports",
+	},
+	{
+		Name:  "report-2023",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "Reports.report-2023",
+	},
+	{
+		Name:  "report-2024",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "Reports.report-2024",
+	},
+	{
+		Name:  "drafts",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "Reports.report-2024.drafts",
+	},
+	{
+		Name:  "archive",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "archive",
+	},
+	{
+		Name:  "old-reports",
+		OrgId: uuid.FromStringOrNil(folder.DefaultOrgID),
+		Paths: "archive.old-reports",
+	},
+	{
+		Name:  "report-2024",
+		OrgId: uuid.FromStringOrNil("38b9879b-f73b-4b0e-b9d9-4fc4c23643a7"),
+		Paths: "report-2024",
+	},
+}
+
+func Test_folder_Search(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		query string
+		opts  folder.SearchOptions
+		want  []string
+		err   error
+	}{
+		{
+			name:  "Exact match",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "report-2024",
+			want:  []string{"Reports.report-2024"},
+		},
+		{
+			name:  "Exact match is case sensitive",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "reports",
+			want:  []string{},
+		},
+		{
+			name:  "Exact match ignoring case",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "reports",
+			opts:  folder.SearchOptions{CaseInsensitive: true},
+			want:  []string{"Reports"},
+		},
+		{
+			name:  "Prefix ranked by depth",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "rep",
+			opts:  folder.SearchOptions{Mode: folder.SearchPrefix, CaseInsensitive: true},
+			want:  []string{"Reports", "Reports.report-2023", "Reports.report-2024"},
+		},
+		{
+			name:  "Substring",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "port",
+			opts:  folder.SearchOptions{Mode: folder.SearchSubstring},
+			want:  []string{"Reports", "Reports.report-2023", "Reports.report-2024", "archive.old-reports"},
+		},
+		{
+			name:  "Glob",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "*-202[4-9]",
+			opts:  folder.SearchOptions{Mode: folder.SearchGlob},
+			want:  []string{"Reports.report-2024"},
+		},
+		{
+			name:  "Restricted to a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "report",
+			opts:  folder.SearchOptions{Mode: folder.SearchSubstring, Subtree: "archive"},
+			want:  []string{"archive.old-reports"},
+		},
+		{
+			name:  "Limited results",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "r",
+			opts:  folder.SearchOptions{Mode: folder.SearchSubstring, Limit: 2},
+			want:  []string{"Reports", "archive"},
+		},
+		{
+			name:  "Fuzzy ranked by similarity",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "raport-2024",
+			opts:  folder.SearchOptions{Mode: folder.SearchFuzzy},
+			want:  []string{"Reports.report-2024", "Reports.report-2023"},
+		},
+		{
+			name:  "Fuzzy with a higher threshold",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "raport-2024",
+			opts:  folder.SearchOptions{Mode: folder.SearchFuzzy, Threshold: 0.9},
+			want:  []string{"Reports.report-2024"},
+		},
+		{
+			name:  "Fuzzy top result",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "ARCHIV",
+			opts:  folder.SearchOptions{Mode: folder.SearchFuzzy, CaseInsensitive: true, Limit: 1},
+			want:  []string{"archive"},
+		},
+		{
+			name:  "Invalid glob",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "[",
+			opts:  folder.SearchOptions{Mode: folder.SearchGlob},
+			want:  []string{},
+			err:   errors.New("error: invalid search pattern"),
+		},
+		{
+			name:  "Invalid subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			query: "report",
+			opts:  folder.SearchOptions{Subtree: "missing"},
+			want:  []string{},
+			err:   errors.New("error: folder does not exist"),
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			query: "report",
+			want:  []string{},
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(searchFolders)
+			get, err := f.Search(tt.orgID, tt.query, tt.opts)
+
+			paths := []string{}
+			for _, f := range get {
+				paths = append(paths, f.Paths)
+			}
+			if !reflect.DeepEqual(paths, tt.want) {
+				t.Errorf("Search() = %v, want %v", paths, tt.want)
+			}
+
+			if tt.err != nil && (err == nil || err.Error() != tt.err.Error()) {
+				t.Errorf("Search() = %v, want %v for error", err, tt.err)
+			} else if tt.err == nil && err != nil {
+				t.Errorf("Search() = %v, want nil for error", err)
+			}
+		})
+	}
+}
+
+func Test_folder_Search_AfterMove(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver(searchFolders)
+
+	if _, err := f.MoveFolder("report-2023", "archive"); err != nil {
+		t.Fatalf("MoveFolder() = %v, want nil for error", err)
+	}
+
+	get, err := f.Search(orgID, "report", folder.SearchOptions{Mode: folder.SearchSubstring, Subtree: "archive"})
+	if err != nil {
+		t.Fatalf("Search() = %v, want nil for error", err)
+	}
+	paths := []string{}
+	for _, f := range get {
+		paths = append(paths, f.Paths)
+	}
+	want := []string{"archive.old-reports", "archive.report-2023"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Search() = %v, want %v", paths, want)
+	}
+
+	// The moved folders can only be found at their new paths
+	if _, err := f.Stats(orgID, "Reports.report-2023"); err == nil {
+		t.Errorf("Stats() = nil, want error for the old path")
+	}
+	if _, err := f.Stats(orgID, "archive.report-2023"); err != nil {
+		t.Errorf("Stats() = %v, want nil for error for the new path", err)
+	}
+}
+
+func Test_folder_Similarity(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		a    string
+		b    string
+		want float64
+	}{
+		{a: "brave-hulk", b: "brave-hulk", want: 1},
+		{a: "brave-hulk", b: "brave-hulc", want: 0.9},
+		{a: "brave-hulk", b: "brvae-hulk", want: 0.8},
+		{a: "alpha", b: "", want: 0},
+		{a: "", b: "", want: 1},
+	}
+
+	for _, tt := range tests {
+		get := folder.Similarity(tt.a, tt.b)
+		if get != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, get, tt.want)
+		}
+	}
+}
+
+func Test_folder_SuggestNames(t *testing.T) {
+	t.Parallel()
+	nodes := []*folder.FileNode{}
+	for _, f := range exampleFolders {
+		nodes = append(nodes, folder.NewFileNode(f))
+	}
+
+	get := folder.SuggestNames(nodes, "alpha.delta.echo", folder.MaxSuggestions, folder.DefaultPathCodec)
+	want := []string{"alpha.delta"}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("SuggestNames() = %q, want %q without the path itself", get, want)
+	}
+}
@@ -3,11 +3,12 @@ package folder
 import (
 	"encoding/json"
 	"fmt"
-	"io"
 	"io/fs"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/lucasepe/codename"
@@ -30,63 +31,77 @@ const MaxDepth = 5
 const DefaultOrgID = "c1556e17-b7c0-45a3-a6ae-9546248fb17a"
 
 type Folder struct {
+	// ID identifies the folder independently of its name, and
+	// is uuid.Nil for folders loaded from data without one
+	ID    uuid.UUID `json:"id"`
 	Name  string    `json:"name"`
 	OrgId uuid.UUID `json:"org_id"`
 	Paths string    `json:"paths"`
+
+	// Metadata holds custom attributes of the folder, such
+	// as its owner, colour or labels
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// CreatedAt and UpdatedAt are the zero time for folders
+	// loaded from data without them. MoveFolder sets UpdatedAt
+	// on every folder whose path it changes.
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// clone returns a copy of the folder with its own Metadata,
+// so folders given to and returned by a driver share no maps
+func (f Folder) clone() Folder {
+	f.Metadata = maps.Clone(f.Metadata)
+	return f
+}
+
+// MarshalJSON leaves out the ID and timestamps of a folder
+// which has none, so such folders are written as they were read
+func (f Folder) MarshalJSON() ([]byte, error) {
+	out := struct {
+		ID        *uuid.UUID        `json:"id,omitempty"`
+		Name      string            `json:"name"`
+		OrgId     uuid.UUID         `json:"org_id"`
+		Paths     string            `json:"paths"`
+		Metadata  map[string]string `json:"metadata,omitempty"`
+		CreatedAt *time.Time        `json:"created_at,omitempty"`
+		UpdatedAt *time.Time        `json:"updated_at,omitempty"`
+	}{
+		Name:     f.Name,
+		OrgId:    f.OrgId,
+		Paths:    f.Paths,
+		Metadata: f.Metadata,
+	}
+	if f.ID != uuid.Nil {
+		out.ID = &f.ID
+	}
+	if !f.CreatedAt.IsZero() {
+		out.CreatedAt = &f.CreatedAt
+	}
+	if !f.UpdatedAt.IsZero() {
+		out.UpdatedAt = &f.UpdatedAt
+	}
+
+	return json.Marshal(out)
 }
 
 func GenerateData() []Folder {
 	rng, _ := codename.DefaultRNG()
-	tree := []Folder{}
+	roots := []genRoot{}
 
 	for i := 0; i < MaxRootSet; i++ {
 		orgId := uuid.FromStringOrNil(DefaultOrgID)
 		if i%3 == 0 {
 			orgId = uuid.Must(uuid.NewV4())
 		}
-
-		name := codename.Generate(rng, 0)
-
-		subtree := make(chan []Folder)
-		go func() {
-			subtree <- generateTree(1, []Folder{
-				{
-					Name:  name,
-					OrgId: orgId,
-					Paths: name,
-				},
-			})
-		}()
-		tree = append(tree, <-subtree...)
+		roots = append(roots, genRoot{orgID: orgId, seed: rng.Int63()})
 	}
 
-	return tree
-}
-
-func generateTree(depth int, tree []Folder) []Folder {
-	rng, _ := codename.DefaultRNG()
-
-	if depth >= MaxDepth {
-		return tree
-	}
-
-	for _, t := range tree {
-		numOfChild := rng.Int()%MaxChild + 1
-		for i := 0; i < numOfChild; i++ {
-			name := codename.Generate(rng, 0)
-
-			childTree := make(chan []Folder)
-			go func() {
-				childTree <- generateTree(depth+1, []Folder{
-					{
-						Name:  name,
-						OrgId: t.OrgId,
-						Paths: t.Paths + "." + name,
-					},
-				})
-			}()
-			tree = append(tree, <-childTree...)
-		}
+	cfg := DefaultGeneratorConfig()
+	cfg.Workers = runtime.GOMAXPROCS(0)
+	tree := []Folder{}
+	for i, nodes := range generateTrees(roots, cfg) {
+		tree = append(tree, buildFolders(roots[i].orgID, nodes)...)
 	}
 
 	return tree
@@ -117,13 +132,10 @@ func GetSampleData() []Folder {
 	}
 	defer file.Close()
 
-	jsonByte, err := io.ReadAll(file)
-	if err != nil {
-		panic(err)
-	}
-
 	folders := []Folder{}
-	err = json.Unmarshal(jsonByte, &folders)
+	err = DecodeFolders(file, LoadOptions{}, func(f Folder) {
+		folders = append(folders, f)
+	})
 	if err != nil {
 		panic(err)
 	}
@@ -0,0 +1,118 @@
+package folder
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gofrs/uuid"
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLtreeLabelLength is the most characters a label of a
+// Postgres ltree may have on every supported version
+const MaxLtreeLabelLength = 255
+
+// NameValidator returns the form a folder name is stored in,
+// or a NameError if the name is not allowed
+type NameValidator func(name string) (string, error)
+
+// LtreeNameValidator returns a NameValidator for names that
+// are ltree labels of at most 'maxLength' characters, which
+// are letters, digits, '_' and '-'. Names are put in Unicode
+// normal form C first, so names which look the same are
+// stored the same.
+func LtreeNameValidator(maxLength int) NameValidator {
+	return func(name string) (string, error) {
+		name = norm.NFC.String(name)
+		if name == "" {
+			return "", &NameError{Name: name, Reason: "it is empty"}
+		}
+		if length := utf8.RuneCountInString(name); length > maxLength {
+			return "", &NameError{Name: name, Reason: fmt.Sprintf("it has %d characters, over %d", length, maxLength)}
+		}
+		for _, r := range name {
+			if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+				return "", &NameError{Name: name, Reason: fmt.Sprintf("it contains %q", r)}
+			}
+		}
+
+		return name, nil
+	}
+}
+
+// DefaultNameValidator is the NameValidator used by drivers
+// unless WithNameValidator is given
+var DefaultNameValidator = LtreeNameValidator(MaxLtreeLabelLength)
+
+// PermissiveNameValidator only rejects empty names, and
+// stores names as they are given. Names may contain '.',
+// which is escaped in their paths by the PathCodec of the
+// driver.
+func PermissiveNameValidator(name string) (string, error) {
+	if name == "" {
+		return "", &NameError{Name: name, Reason: "it is empty"}
+	}
+
+	return name, nil
+}
+
+// WithNameValidator makes the driver check folder names
+// using 'validate' in place of DefaultNameValidator
+func WithNameValidator(validate NameValidator) DriverOption {
+	return func(d *driver) {
+		d.validateName = validate
+	}
+}
+
+// normalizeFolder returns 'f' with its name and every name
+// in its path, split using 'codec', in the form 'validate'
+// stores them, or the error for the first name which is
+// not allowed
+func normalizeFolder(f Folder, validate NameValidator, codec PathCodec) (Folder, error) {
+	name, err := validate(f.Name)
+	if err != nil {
+		return Folder{}, err
+	}
+
+	sections := codec.Split(f.Paths)
+	for i, section := range sections {
+		if sections[i], err = validate(section); err != nil {
+			return Folder{}, err
+		}
+	}
+
+	f.Name = name
+	f.Paths = codec.Join(sections)
+	return f, nil
+}
+
+// normalizeRef returns 'ref', the ID, path or name of a
+// folder, with every name in it in the form the driver stores
+// names in, so a ref finds the folders it looks like. A ref
+// with a name which is not allowed is returned as it is.
+func (f *driver) normalizeRef(ref string) string {
+	if _, err := uuid.FromString(ref); err == nil {
+		return ref
+	}
+
+	sections := f.codec.Split(ref)
+	changed := false
+	for i, section := range sections {
+		name, err := f.validateName(section)
+		if err != nil {
+			return ref
+		}
+		changed = changed || name != section
+		sections[i] = name
+	}
+	if !changed {
+		return ref
+	}
+	if strings.HasPrefix(ref, f.codec.Prefix) {
+		return f.codec.Join(sections)
+	}
+
+	return f.codec.joinNames(sections)
+}
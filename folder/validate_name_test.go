@@ -0,0 +1,194 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_LtreeNameValidator(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{
+			name:  "Letters and digits",
+			input: "alpha42",
+			want:  "alpha42",
+		},
+		{
+			name:  "Underscores and hyphens",
+			input: "noble_vixen-2",
+			want:  "noble_vixen-2",
+		},
+		{
+			name:  "Decomposed accent is composed",
+			input: "cafe\u0301",
+			want:  "caf\u00e9",
+		},
+		{
+			name:  "Empty name",
+			input: "",
+			err:   errors.New(`error: invalid folder name "", as it is empty`),
+		},
+		{
+			name:  "Too long",
+			input: "abcdefghijklmnopq",
+			err:   errors.New(`error: invalid folder name "abcdefghijklmnopq", as it has 17 characters, over 16`),
+		},
+		{
+			name:  "Path separator",
+			input: "alpha.bravo",
+			err:   errors.New(`error: invalid folder name "alpha.bravo", as it contains '.'`),
+		},
+		{
+			name:  "Space",
+			input: "alpha bravo",
+			err:   errors.New(`error: invalid folder name "alpha bravo", as it contains ' '`),
+		},
+	}
+
+	validate := folder.LtreeNameValidator(16)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			get, err := validate(tt.input)
+
+			if tt.err != nil {
+				if err == nil || err.Error() != tt.err.Error() {
+					t.Errorf("LtreeNameValidator() = %v, want %v for error", err, tt.err)
+				} else if !errors.Is(err, folder.ErrInvalidName) {
+					t.Errorf("LtreeNameValidator() = %v, want a NameError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LtreeNameValidator() = %v, want nil for error", err)
+			}
+			if get != tt.want {
+				t.Errorf("LtreeNameValidator() = %q, want %q", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_LoadDriver(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	folders := []folder.Folder{
+		{Name: "alpha", OrgId: orgID, Paths: "alpha"},
+		{Name: "cafe\u0301", OrgId: orgID, Paths: "alpha.cafe\u0301"},
+		{Name: "my docs", OrgId: orgID, Paths: "alpha.my docs"},
+	}
+
+	_, err := folder.LoadDriver(folders)
+	recordErr := &folder.RecordError{}
+	if !errors.As(err, &recordErr) || recordErr.Index != 2 || !errors.Is(err, folder.ErrInvalidName) {
+		t.Errorf("LoadDriver() = %v, want a NameError for record 2", err)
+	}
+	want := `error: record 2: invalid folder name "my docs", as it contains ' '`
+	if err == nil || err.Error() != want {
+		t.Errorf("LoadDriver() = %v, want %v for error", err, want)
+	}
+
+	f, err := folder.LoadDriver(folders[:2])
+	if err != nil {
+		t.Fatalf("LoadDriver() = %v, want nil for error", err)
+	}
+	children := f.GetAllChildFolders(orgID, "alpha")
+	if len(children) != 1 || children[0].Name != "caf\u00e9" || children[0].Paths != "alpha.caf\u00e9" {
+		t.Errorf("GetAllChildFolders() = %v, want the name normalized", children)
+	}
+
+	f, err = folder.LoadDriver(folders, folder.WithNameValidator(folder.PermissiveNameValidator))
+	if err != nil {
+		t.Fatalf("LoadDriver() = %v, want nil for error with PermissiveNameValidator", err)
+	}
+	if _, err := f.CreateFolder(orgID, "", "alpha"); !errors.Is(err, folder.ErrInvalidName) {
+		t.Errorf("CreateFolder() = %v, want a NameError", err)
+	}
+}
+
+func Test_folder_NewDriver_InvalidName(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	folders := []folder.Folder{
+		{Name: "My Folder", OrgId: orgID, Paths: "My Folder"},
+		{Name: "v1.2 release", OrgId: orgID, Paths: `My Folder.v1\.2 release`},
+	}
+
+	// Names the validator does not allow are loaded as they
+	// are given, but are still rejected when creating folders
+	f := folder.NewDriver(folders)
+	parent, err := f.GetParent(orgID, `My Folder.v1\.2 release`)
+	if err != nil || !reflect.DeepEqual(parent, folders[0]) {
+		t.Errorf("GetParent() = %v, %v, want %v", parent, err, folders[0])
+	}
+	if _, err := f.CreateFolder(orgID, "v1.3 release", "My Folder"); !errors.Is(err, folder.ErrInvalidName) {
+		t.Errorf("CreateFolder() = %v, want a NameError", err)
+	}
+
+	if _, err := folder.LoadDriver(folders); !errors.Is(err, folder.ErrInvalidName) {
+		t.Errorf("LoadDriver() = %v, want a NameError", err)
+	}
+}
+
+func Test_folder_NewDriverFromReader_InvalidName(t *testing.T) {
+	t.Parallel()
+	input := `[
+		{"name": "alpha", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha"},
+		{"name": "my docs", "org_id": "` + folder.DefaultOrgID + `", "paths": "alpha.my docs"}
+	]`
+
+	skipped := []int{}
+	f, err := folder.NewDriverFromReader(strings.NewReader(input), folder.LoadOptions{
+		OnError: func(err *folder.RecordError) error {
+			skipped = append(skipped, err.Index)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewDriverFromReader() = %v, want nil for error", err)
+	}
+	if len(skipped) != 1 || skipped[0] != 1 {
+		t.Errorf("NewDriverFromReader() skipped %v, want record 1", skipped)
+	}
+	if get := f.GetFoldersByOrgID(uuid.FromStringOrNil(folder.DefaultOrgID)); len(get) != 1 {
+		t.Errorf("GetFoldersByOrgID() = %v, want only alpha", get)
+	}
+}
+
+func Test_folder_NormalizedRefs(t *testing.T) {
+	t.Parallel()
+	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
+	f := folder.NewDriver([]folder.Folder{
+		{Name: "café", OrgId: orgID, Paths: "café"},
+		{Name: "menu", OrgId: orgID, Paths: "café.menu"},
+		{Name: "golf", OrgId: orgID, Paths: "golf"},
+	})
+
+	// "cafe\u0301" looks the same as "café", but is
+	// only found once it is normalized
+	decomposed := "cafe\u0301"
+	if _, err := f.GetParent(orgID, decomposed+".menu"); err != nil {
+		t.Errorf("GetParent() = %v, want nil for error", err)
+	}
+	if get := f.GetAllChildFolders(orgID, decomposed); len(get) != 1 {
+		t.Errorf("GetAllChildFolders() = %v, want menu", get)
+	}
+	if _, err := f.CreateFolder(orgID, "drinks", decomposed); err != nil {
+		t.Errorf("CreateFolder() = %v, want nil for error", err)
+	}
+	if _, err := f.DryRunMove(orgID, decomposed, "golf"); err != nil {
+		t.Errorf("DryRunMove() = %v, want nil for error", err)
+	}
+	if _, err := f.MoveFolder(decomposed, "golf"); err != nil {
+		t.Errorf("MoveFolder() = %v, want nil for error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package folder
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/gofrs/uuid"
+)
+
+// WalkOrder is the order in which Walk visits folders
+type WalkOrder int
+
+const (
+	// PreOrder visits a folder before the folders below it
+	PreOrder WalkOrder = iota
+	// PostOrder visits a folder after the folders below it
+	PostOrder
+	// BreadthFirst visits every folder on a level before
+	// moving down to the next one
+	BreadthFirst
+)
+
+// Visit is a folder reached while walking a tree
+type Visit struct {
+	Folder Folder
+	// Depth is the number of levels below the folder
+	// the walk started at
+	Depth int
+
+	skip *bool
+}
+
+// SkipChildren stops the walk from visiting the folders
+// below this one. It has no effect in PostOrder, where they
+// have already been visited.
+func (v Visit) SkipChildren() {
+	if v.skip != nil {
+		*v.skip = true
+	}
+}
+
+// WalkNodes returns an iterator visiting the subtrees starting
+// at each FileNode in 'start', in the given order. Folders are
+// visited lazily, so the tree must not be changed while walking.
+func WalkNodes(start []*FileNode, order WalkOrder) iter.Seq[Visit] {
+	return func(yield func(Visit) bool) {
+		// The same flag is shared by every Visit, and
+		// cleared before each one is yielded
+		skip := false
+		visit := func(fileNode *FileNode, depth int) (bool, bool) {
+			skip = false
+			ok := yield(Visit{Folder: fileNode.file.clone(), Depth: depth, skip: &skip})
+			return ok, skip
+		}
+
+		switch order {
+		case PreOrder:
+			var walk func(fileNode *FileNode, depth int) bool
+			walk = func(fileNode *FileNode, depth int) bool {
+				ok, skipped := visit(fileNode, depth)
+				if !ok {
+					return false
+				}
+				if skipped {
+					return true
+				}
+				for _, childNode := range fileNode.children {
+					if !walk(childNode, depth+1) {
+						return false
+					}
+				}
+				return true
+			}
+			for _, fileNode := range start {
+				if !walk(fileNode, 0) {
+					return
+				}
+			}
+
+		case PostOrder:
+			var walk func(fileNode *FileNode, depth int) bool
+			walk = func(fileNode *FileNode, depth int) bool {
+				for _, childNode := range fileNode.children {
+					if !walk(childNode, depth+1) {
+						return false
+					}
+				}
+				ok, _ := visit(fileNode, depth)
+				return ok
+			}
+			for _, fileNode := range start {
+				if !walk(fileNode, 0) {
+					return
+				}
+			}
+
+		case BreadthFirst:
+			level := start
+			for depth := 0; len(level) > 0; depth++ {
+				next := []*FileNode{}
+				for _, fileNode := range level {
+					ok, skipped := visit(fileNode, depth)
+					if !ok {
+						return
+					}
+					if !skipped {
+						next = append(next, fileNode.children...)
+					}
+				}
+				level = next
+			}
+		}
+	}
+}
+
+// Walk returns an iterator visiting the folder with 'path'
+// and every folder below it in the given order, or every
+// folder in the organization if 'path' is empty
+func (f *driver) Walk(orgID uuid.UUID, path string, order WalkOrder) (iter.Seq[Visit], error) {
+	if order < PreOrder || order > BreadthFirst {
+		return WalkNodes(nil, PreOrder), errors.New("error: unknown walk order")
+	}
+
+	if path == "" {
+		org, err := f.findOrg(orgID)
+		if err != nil {
+			return WalkNodes(nil, order), err
+		}
+		return WalkNodes(FindRootNodes(org.folders), order), nil
+	}
+
+	fileNode, err := f.findNode(orgID, path)
+	if err != nil {
+		return WalkNodes(nil, order), err
+	}
+
+	return WalkNodes([]*FileNode{fileNode}, order), nil
+}
@@ -0,0 +1,144 @@
+package folder_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/georgechieng-sc/interns-2022/folder"
+	"github.com/gofrs/uuid"
+)
+
+func Test_folder_Walk(t *testing.T) {
+	t.Parallel()
+	tests := [...]struct {
+		name  string
+		orgID uuid.UUID
+		path  string
+		order folder.WalkOrder
+		skip  string
+		stop  string
+		want  []string
+		err   error
+	}{
+		{
+			name:  "Pre-order over a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.PreOrder,
+			want:  []string{"alpha", "alpha.bravo", "alpha.bravo.charlie", "alpha.delta", "alpha.delta.echo"},
+		},
+		{
+			name:  "Post-order over a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.PostOrder,
+			want:  []string{"alpha.bravo.charlie", "alpha.bravo", "alpha.delta.echo", "alpha.delta", "alpha"},
+		},
+		{
+			name:  "Breadth-first over a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.BreadthFirst,
+			want:  []string{"alpha", "alpha.bravo", "alpha.delta", "alpha.bravo.charlie", "alpha.delta.echo"},
+		},
+		{
+			name:  "Whole organization",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			order: folder.BreadthFirst,
+			want:  []string{"alpha", "golf", "alpha.bravo", "alpha.delta", "alpha.bravo.charlie", "alpha.delta.echo"},
+		},
+		{
+			name:  "Pre-order skipping a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.PreOrder,
+			skip:  "alpha.bravo",
+			want:  []string{"alpha", "alpha.bravo", "alpha.delta", "alpha.delta.echo"},
+		},
+		{
+			name:  "Breadth-first skipping a subtree",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.BreadthFirst,
+			skip:  "alpha.delta",
+			want:  []string{"alpha", "alpha.bravo", "alpha.delta", "alpha.bravo.charlie"},
+		},
+		{
+			name:  "Post-order ignores skipping",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.PostOrder,
+			skip:  "alpha.bravo",
+			want:  []string{"alpha.bravo.charlie", "alpha.bravo", "alpha.delta.echo", "alpha.delta", "alpha"},
+		},
+		{
+			name:  "Stopping early",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha",
+			order: folder.PostOrder,
+			stop:  "alpha.delta.echo",
+			want:  []string{"alpha.bravo.charlie", "alpha.bravo", "alpha.delta.echo"},
+		},
+		{
+			name:  "Invalid path",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID),
+			path:  "alpha.echo",
+			order: folder.PreOrder,
+			want:  []string{},
+			err:   errors.New("error: folder does not exist"),
+		},
+		{
+			name:  "Invalid UUID",
+			orgID: uuid.FromStringOrNil(folder.DefaultOrgID + "."),
+			order: folder.PreOrder,
+			want:  []string{},
+			err:   errors.New("error: organization does not exist"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := folder.NewDriver(exampleFolders)
+			seq, err := f.Walk(tt.orgID, tt.path, tt.order)
+			checkNotFound(t, "Walk()", err, tt.err)
+
+			get := []string{}
+			for visit := range seq {
+				get = append(get, visit.Folder.Paths)
+				if visit.Folder.Paths == tt.skip {
+					visit.SkipChildren()
+				}
+				if visit.Folder.Paths == tt.stop {
+					break
+				}
+			}
+
+			if !reflect.DeepEqual(get, tt.want) {
+				t.Errorf("Walk() = %v, want %v", get, tt.want)
+			}
+		})
+	}
+}
+
+func Test_folder_Walk_Depth(t *testing.T) {
+	t.Parallel()
+	f := folder.NewDriver(exampleFolders)
+	seq, err := f.Walk(uuid.FromStringOrNil(folder.DefaultOrgID), "alpha.delta", folder.PreOrder)
+	if err != nil {
+		t.Fatalf("Walk() = %v, want nil for error", err)
+	}
+
+	get := map[string]int{}
+	for visit := range seq {
+		get[visit.Folder.Paths] = visit.Depth
+	}
+
+	want := map[string]int{
+		"alpha.delta":      0,
+		"alpha.delta.echo": 1,
+	}
+	if !reflect.DeepEqual(get, want) {
+		t.Errorf("Walk() depths = %v, want %v", get, want)
+	}
+}
@@ -1,11 +1,12 @@
 module github.com/georgechieng-sc/interns-2022
 
-go 1.23
+go 1.23.0
 
 require (
 	github.com/gofrs/uuid v4.3.0+incompatible
 	github.com/lucasepe/codename v0.2.0
 	github.com/stretchr/testify v1.9.0
+	golang.org/x/text v0.28.0
 )
 
 require (
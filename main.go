@@ -1,13 +1,36 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"os"
 
 	"github.com/georgechieng-sc/interns-2022/folder"
 	"github.com/gofrs/uuid"
 )
 
+// orphanPolicies are the values of the -orphans flag
+var orphanPolicies = map[string]folder.OrphanPolicy{
+	"keep":       folder.OrphanKeep,
+	"reject":     folder.OrphanReject,
+	"lost-found": folder.OrphanLostFound,
+	"create":     folder.OrphanCreateAncestors,
+}
+
 func main() {
+	repair := flag.String("repair", "", "repair the JSON folders in this file, writing them to stdout and the changes to stderr")
+	orphans := flag.String("orphans", "keep", "with -repair, what to do with folders whose parents are missing: keep, reject, lost-found or create")
+	dropDuplicates := flag.Bool("drop-duplicates", false, "with -repair, remove folders with a name already used rather than renaming them")
+	flag.Parse()
+
+	if *repair != "" {
+		if err := repairFile(*repair, *orphans, *dropDuplicates); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	orgID := uuid.FromStringOrNil(folder.DefaultOrgID)
 
 	res := folder.GetAllFolders()
@@ -20,3 +43,39 @@ func main() {
 	fmt.Printf("\n Folders for orgID: %s", orgID)
 	folder.PrettyPrint(orgFolder)
 }
+
+// repairFile repairs the folders in the file with 'path',
+// so they can be loaded by NewDriver
+func repairFile(path string, orphans string, dropDuplicates bool) error {
+	policy := folder.RepairPolicy{}
+	orphanPolicy, exists := orphanPolicies[orphans]
+	if !exists {
+		return fmt.Errorf("error: unknown orphan policy %q", orphans)
+	}
+	policy.Orphans = orphanPolicy
+	if dropDuplicates {
+		policy.Duplicates = folder.DuplicateRemove
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error: reading folders: %w", err)
+	}
+	defer file.Close()
+
+	folders := []folder.Folder{}
+	err = folder.DecodeFolders(file, folder.LoadOptions{}, func(f folder.Folder) {
+		folders = append(folders, f)
+	})
+	if err != nil {
+		return err
+	}
+
+	repaired, actions := folder.Repair(folders, policy)
+	for _, action := range actions {
+		fmt.Fprintln(os.Stderr, action)
+	}
+	fmt.Println(string(folder.MarshalJson(repaired)))
+
+	return nil
+}